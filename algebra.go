@@ -0,0 +1,207 @@
+package rrule
+
+import (
+	"container/heap"
+	"time"
+)
+
+// Iterable is a source of occurrences in ascending order, such as RRule,
+// Set and BusinessDayRule.
+type Iterable interface {
+	Iterator() Next
+}
+
+type setOperation int
+
+const (
+	unionOperation setOperation = iota
+	intersectionOperation
+	differenceOperation
+)
+
+// Combination is a lazy set operation on recurrence sources, it is an
+// Iterable itself so that operations can be nested.
+type Combination struct {
+	operation setOperation
+	sources   []Iterable
+}
+
+// Union returns the occurrences of any of the sources.
+func Union(sources ...Iterable) *Combination {
+	return &Combination{operation: unionOperation, sources: sources}
+}
+
+// Intersection returns the occurrences common to all the sources.
+// Between and Before stop the search at their upper bound, but All, After
+// and the Iterator of infinite sources without a common occurrence search
+// until the sources end, e.g. for ever for two daily rules at different
+// hours.
+func Intersection(sources ...Iterable) *Combination {
+	return &Combination{operation: intersectionOperation, sources: sources}
+}
+
+// Difference returns the occurrences of base which none of the excluded
+// sources has. As for Intersection, only Between and Before bound the
+// search when the excluded sources have all the later occurrences of base.
+func Difference(base Iterable, excluded ...Iterable) *Combination {
+	return &Combination{operation: differenceOperation, sources: append([]Iterable{base}, excluded...)}
+}
+
+// peekIterator holds the next occurrence of an iterator.
+type peekIterator struct {
+	next Next
+	dt   time.Time
+	ok   bool
+}
+
+// newPeekIterator returns the iterator of source, a Combination stopping
+// after bound unless it is zero.
+func newPeekIterator(source Iterable, bound time.Time) *peekIterator {
+	var next Next
+	if c, ok := source.(*Combination); ok {
+		next = c.iterator(bound)
+	} else {
+		next = source.Iterator()
+	}
+	p := &peekIterator{next: next}
+	p.dt, p.ok = p.next()
+	return p
+}
+
+// skipBefore advances the iterator to its first occurrence not before dt.
+func (p *peekIterator) skipBefore(dt time.Time) {
+	for p.ok && p.dt.Before(dt) {
+		p.dt, p.ok = p.next()
+	}
+}
+
+// Iterator returns an iterator for Combination.
+func (c *Combination) Iterator() Next {
+	return c.iterator(time.Time{})
+}
+
+// iterator returns an iterator ending at the first candidate occurrence
+// after bound, so that the search of an intersection or a difference ends
+// even if it has no further occurrence. A zero bound does not end it.
+func (c *Combination) iterator(bound time.Time) Next {
+	iterators := make([]*peekIterator, len(c.sources))
+	for i, source := range c.sources {
+		iterators[i] = newPeekIterator(source, bound)
+	}
+	switch c.operation {
+	case intersectionOperation:
+		return intersectionIterator(iterators, bound)
+	case differenceOperation:
+		return differenceIterator(iterators[0], unionIterator(iterators[1:]), bound)
+	default:
+		return unionIterator(iterators)
+	}
+}
+
+func unionIterator(iterators []*peekIterator) Next {
+	h := &genHeap{}
+	for _, p := range iterators {
+		if p.ok {
+			heap.Push(h, genItem{p.dt, p.next})
+		}
+	}
+	return func() (time.Time, bool) {
+		if h.Len() == 0 {
+			return time.Time{}, false
+		}
+		dt := (*h)[0].dt
+		// Skip the same occurrence in the other sources.
+		for h.Len() != 0 && !(*h)[0].dt.After(dt) {
+			h.advance()
+		}
+		return dt, true
+	}
+}
+
+func intersectionIterator(iterators []*peekIterator, bound time.Time) Next {
+	return func() (time.Time, bool) {
+		if len(iterators) == 0 {
+			return time.Time{}, false
+		}
+		for {
+			var max time.Time
+			for _, p := range iterators {
+				if !p.ok {
+					return time.Time{}, false
+				}
+				if p.dt.After(max) {
+					max = p.dt
+				}
+			}
+			if !bound.IsZero() && max.After(bound) {
+				return time.Time{}, false
+			}
+			common := true
+			for _, p := range iterators {
+				p.skipBefore(max)
+				if !p.ok {
+					return time.Time{}, false
+				}
+				common = common && p.dt.Equal(max)
+			}
+			if common {
+				for _, p := range iterators {
+					p.dt, p.ok = p.next()
+				}
+				return max, true
+			}
+		}
+	}
+}
+
+func differenceIterator(base *peekIterator, excluded Next, bound time.Time) Next {
+	ex := &peekIterator{next: excluded}
+	ex.dt, ex.ok = ex.next()
+	var last time.Time
+	return func() (time.Time, bool) {
+		for base.ok {
+			dt := base.dt
+			if !bound.IsZero() && dt.After(bound) {
+				return time.Time{}, false
+			}
+			base.dt, base.ok = base.next()
+			if !last.IsZero() && !dt.After(last) {
+				continue
+			}
+			last = dt
+			ex.skipBefore(dt)
+			if !ex.ok || !ex.dt.Equal(dt) {
+				return dt, true
+			}
+		}
+		return time.Time{}, false
+	}
+}
+
+// All returns all occurrences of the Combination.
+func (c *Combination) All() []time.Time {
+	return all(c.Iterator())
+}
+
+// Between returns all the occurrences of the Combination between after and before.
+// The inc keyword defines what happens if after and/or before are themselves occurrences.
+// With inc == True, they will be included in the list, if they are found in the recurrence set.
+func (c *Combination) Between(after, before time.Time, inc bool) []time.Time {
+	return between(c.iterator(before), after, before, inc)
+}
+
+// Before returns the last occurrence before the given datetime instance,
+// or time.Time's zero value if no occurrence match.
+// The inc keyword defines what happens if dt is an occurrence.
+// With inc == True, if dt itself is an occurrence, it will be returned.
+func (c *Combination) Before(dt time.Time, inc bool) time.Time {
+	return before(c.iterator(dt), dt, inc)
+}
+
+// After returns the first occurrence after the given datetime instance,
+// or time.Time's zero value if no occurrence match.
+// The inc keyword defines what happens if dt is an occurrence.
+// With inc == True, if dt itself is an occurrence, it will be returned.
+func (c *Combination) After(dt time.Time, inc bool) time.Time {
+	return after(c.Iterator(), dt, inc)
+}
@@ -0,0 +1,72 @@
+package rrule
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAlgebra(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	everyTwoDays, _ := NewRRule(ROption{Freq: Daily, Interval: 2, Count: 6, Dtstart: dtstart})
+	everyThreeDays, _ := NewRRule(ROption{Freq: Daily, Interval: 3, Count: 4, Dtstart: dtstart})
+	set := &Set{}
+	set.DTStart(dtstart)
+	set.RDate(time.Date(2024, 6, 4, 9, 0, 0, 0, time.UTC))
+	set.RDate(time.Date(2024, 6, 9, 9, 0, 0, 0, time.UTC))
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 9, 0, 0, 0, time.UTC) }
+
+	assert.Equal(t, []time.Time{day(3), day(4), day(5), day(6), day(7), day(9), day(11), day(12), day(13)},
+		Union(everyTwoDays, everyThreeDays, set).All())
+	assert.Equal(t, []time.Time{day(3), day(9)}, Intersection(everyTwoDays, everyThreeDays).All())
+	assert.Equal(t, []time.Time{day(9)}, Intersection(everyTwoDays, everyThreeDays, set).All())
+	assert.Equal(t, []time.Time{day(5), day(7), day(11), day(13)}, Difference(everyTwoDays, everyThreeDays).All())
+	assert.Equal(t, []time.Time{day(5), day(7), day(11), day(13)},
+		Difference(Union(everyTwoDays, set), everyThreeDays, set).All())
+	assert.Empty(t, Intersection().All())
+
+	// Infinite sources stay lazy.
+	daily, _ := NewRRule(ROption{Freq: Daily, Dtstart: dtstart})
+	weekly, _ := NewRRule(ROption{Freq: Weekly, Dtstart: dtstart})
+	assert.Equal(t, day(10), Intersection(daily, weekly).After(day(4), false))
+	assert.Equal(t, []time.Time{day(8), day(9)},
+		Difference(daily, weekly).Between(day(7), day(11), false))
+	assert.Equal(t, day(9), Union(daily, weekly).Before(day(10), false))
+
+	// Between and Before end without a common occurrence, even nested.
+	odd, _ := NewRRule(ROption{Freq: Secondly, Interval: 2, Dtstart: dtstart.Add(time.Second)})
+	even, _ := NewRRule(ROption{Freq: Secondly, Interval: 2, Dtstart: dtstart})
+	assert.Empty(t, Intersection(odd, even).Between(day(3), day(4), true))
+	assert.True(t, Intersection(odd, even).Before(day(4), true).IsZero())
+	assert.Empty(t, Difference(even, even).Between(day(3), day(4), true))
+	assert.Empty(t, Union(weekly, Intersection(odd, even)).Between(day(4), day(5), true))
+	assert.Equal(t, []time.Time{day(10)}, Intersection(daily, Union(weekly, Intersection(odd, even))).Between(day(4), day(11), false))
+}
+
+func BenchmarkUnionIterator(b *testing.B) {
+	dtstart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, k := range []int{1, 10, 100, 1000} {
+		sources := make([]Iterable, k)
+		for i := range sources {
+			r, err := NewRRule(ROption{Freq: Hourly, Dtstart: dtstart.Add(time.Duration(i) * time.Second)})
+			if err != nil {
+				b.Fatal(err)
+			}
+			sources[i] = r
+		}
+		union := Union(sources...)
+		b.Run(fmt.Sprintf("%d sources", k), func(b *testing.B) {
+			next := union.Iterator()
+			b.ResetTimer()
+			// Each operation generates one occurrence.
+			for i := 0; i < b.N; i++ {
+				if _, ok := next(); !ok {
+					b.Fatal("expected an occurrence")
+				}
+			}
+		})
+	}
+}
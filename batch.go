@@ -0,0 +1,115 @@
+package rrule
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// expandCheckInterval is the number of occurrences generated between two
+// checks of the context.
+const expandCheckInterval = 256
+
+// Occurrence is an occurrence of the source at Index of a batch.
+type Occurrence struct {
+	Index int
+	Time  time.Time
+}
+
+// Expand generates the occurrences of sources within [start, end) on a pool
+// of workers, GOMAXPROCS when workers is not above 0, calling fn for each one.
+// fn is called concurrently from the workers, the occurrences of a source in
+// order from the same worker.
+// Expand stops at the first error returned by fn or when ctx is done, and
+// returns that error.
+func Expand(ctx context.Context, sources []Iterable, start, end time.Time, workers int, fn func(Occurrence) error) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(sources) {
+		workers = len(sources)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		next     int64 = -1
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= len(sources) {
+					return
+				}
+				if err := expandSource(ctx, i, sources[i], start, end, fn); err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// ExpandStream is Expand sending the occurrences to the returned channel,
+// which is closed once all of them are sent or ctx is done. The returned wait
+// function blocks until the channel is closed and returns the error of
+// Expand, nil when the stream is complete. A consumer that stops receiving
+// early must cancel ctx before calling wait.
+func ExpandStream(ctx context.Context, sources []Iterable, start, end time.Time, workers int) (<-chan Occurrence, func() error) {
+	ch := make(chan Occurrence, expandCheckInterval)
+	done := make(chan struct{})
+	var err error
+	go func() {
+		defer close(ch)
+		defer close(done)
+		err = Expand(ctx, sources, start, end, workers, func(o Occurrence) error {
+			select {
+			case ch <- o:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return ch, func() error {
+		<-done
+		return err
+	}
+}
+
+func expandSource(ctx context.Context, index int, source Iterable, start, end time.Time, fn func(Occurrence) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	next := source.Iterator()
+	for n := 1; ; n++ {
+		if n%expandCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		dt, ok := next()
+		if !ok || !dt.Before(end) {
+			return nil
+		}
+		if dt.Before(start) {
+			continue
+		}
+		if err := fn(Occurrence{Index: index, Time: dt}); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package rrule
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func batchSources(t testing.TB, n int) []Iterable {
+	dtstart := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	sources := make([]Iterable, n)
+	for i := range sources {
+		r, err := NewRRule(ROption{Freq: Daily, Interval: i%5 + 1, Dtstart: dtstart.Add(time.Duration(i) * time.Minute)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i%2 == 0 {
+			sources[i] = r
+			continue
+		}
+		set := &Set{}
+		set.RRule(r)
+		set.ExDate(dtstart.AddDate(0, 0, 10).Add(time.Duration(i) * time.Minute))
+		sources[i] = set
+	}
+	return sources
+}
+
+func TestExpand(t *testing.T) {
+	t.Parallel()
+	sources := batchSources(t, 50)
+	start := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	var mu sync.Mutex
+	got := make([][]time.Time, len(sources))
+	err := Expand(context.Background(), sources, start, end, 4, func(o Occurrence) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got[o.Index] = append(got[o.Index], o.Time)
+		return nil
+	})
+	assert.NoError(t, err)
+	for i, source := range sources {
+		assert.Equal(t, between(source.Iterator(), start, end, false), got[i])
+	}
+
+	streamed := make([][]time.Time, len(sources))
+	ch, wait := ExpandStream(context.Background(), sources, start, end, 0)
+	for o := range ch {
+		streamed[o.Index] = append(streamed[o.Index], o.Time)
+	}
+	assert.NoError(t, wait())
+	assert.Equal(t, got, streamed)
+}
+
+func TestExpandStop(t *testing.T) {
+	t.Parallel()
+	sources := batchSources(t, 50)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(10, 0, 0)
+
+	errStop := errors.New("stop")
+	var mu sync.Mutex
+	calls := 0
+	err := Expand(context.Background(), sources, start, end, 4, func(o Occurrence) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 100 {
+			return errStop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStop)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = Expand(ctx, sources, start, end, 4, func(o Occurrence) error { return nil })
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// The stream truncated by the cancellation tells so.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	ch, wait := ExpandStream(ctx, sources, start, end, 4)
+	var received []Occurrence
+	for o := range ch {
+		if received = append(received, o); len(received) == 10 {
+			cancel()
+		}
+	}
+	assert.ErrorIs(t, wait(), context.Canceled)
+	var total int64
+	assert.NoError(t, Expand(context.Background(), sources, start, end, 4, func(o Occurrence) error {
+		atomic.AddInt64(&total, 1)
+		return nil
+	}))
+	assert.GreaterOrEqual(t, len(received), 10)
+	assert.Less(t, int64(len(received)), total)
+	for _, o := range received {
+		assert.True(t, !o.Time.Before(start) && o.Time.Before(end))
+	}
+	_, ok := <-ch
+	assert.False(t, ok)
+	assert.NoError(t, Expand(context.Background(), nil, start, end, 0, nil))
+}
+
+func BenchmarkExpand(b *testing.B) {
+	sources := batchSources(b, 1000)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 3, 0)
+	for i := 0; i < b.N; i++ {
+		_ = Expand(context.Background(), sources, start, end, 0, func(o Occurrence) error { return nil })
+	}
+}
@@ -0,0 +1,96 @@
+package rrule
+
+import (
+	"sync"
+	"time"
+)
+
+// occurrenceCache memoizes the first occurrences of a recurrence, generating
+// them once for all the iterators sharing it. It is safe for concurrent use.
+type occurrenceCache struct {
+	size int
+
+	mu    sync.Mutex
+	times []time.Time
+	next  Next
+	done  bool
+	// version is the version of the rule the occurrences were generated
+	// with, see validate.
+	version uint64
+}
+
+func newOccurrenceCache(size int) *occurrenceCache {
+	if size <= 0 {
+		return nil
+	}
+	return &occurrenceCache{size: size}
+}
+
+// renew returns an empty cache of the same size, nil when c is nil.
+func (c *occurrenceCache) renew() *occurrenceCache {
+	if c == nil {
+		return nil
+	}
+	return newOccurrenceCache(c.size)
+}
+
+// validate empties the cache when the rule generating its occurrences was
+// modified since, version being the current version of the rule.
+func (c *occurrenceCache) validate(version uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version != version {
+		c.times, c.next, c.done = nil, nil, false
+		c.version = version
+	}
+}
+
+// iterator returns an iterator reading the cached occurrences and generating
+// the missing ones with source. Once the cache is full, the occurrences after
+// the cached ones are generated by a new source iterator.
+func (c *occurrenceCache) iterator(source func() Next) Next {
+	i := 0
+	var fallback Next
+	return func() (time.Time, bool) {
+		if fallback != nil {
+			return fallback()
+		}
+		c.mu.Lock()
+		if i < len(c.times) {
+			dt := c.times[i]
+			i++
+			c.mu.Unlock()
+			return dt, true
+		}
+		if c.done {
+			c.mu.Unlock()
+			return time.Time{}, false
+		}
+		if len(c.times) < c.size {
+			if c.next == nil {
+				c.next = source()
+			}
+			dt, ok := c.next()
+			if !ok {
+				c.done = true
+				c.next = nil
+				c.mu.Unlock()
+				return time.Time{}, false
+			}
+			c.times = append(c.times, dt)
+			i++
+			c.mu.Unlock()
+			return dt, true
+		}
+		last := c.times[len(c.times)-1]
+		c.mu.Unlock()
+
+		fallback = source()
+		for dt, ok := fallback(); ok; dt, ok = fallback() {
+			if dt.After(last) {
+				return dt, true
+			}
+		}
+		return time.Time{}, false
+	}
+}
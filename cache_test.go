@@ -0,0 +1,91 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRRuleCache(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	r, _ := NewRRule(ROption{Freq: Daily, Count: 20, Dtstart: dtstart})
+	want := r.All()
+
+	r.EnableCache(5)
+	// The occurrences after the cached ones are generated again.
+	assert.Equal(t, want, r.All())
+	assert.Equal(t, want, r.All())
+	assert.Equal(t, want[2:4], r.Between(want[2], want[3], true))
+	assert.Equal(t, want[12], r.After(want[11], false))
+	assert.Equal(t, want[3], r.Before(want[4], false))
+	assert.Len(t, r.cache.times, 5)
+
+	r.DTStart(dtstart.AddDate(0, 0, 1))
+	assert.NotNil(t, r.cache)
+	assert.Empty(t, r.cache.times)
+	assert.Equal(t, want[1:4], r.Between(want[0], want[3], true))
+
+	r.Until(want[2])
+	assert.Equal(t, want[1:3], r.All())
+
+	r.EnableCache(0)
+	assert.Nil(t, r.cache)
+	assert.Equal(t, want[1:3], r.All())
+}
+
+func TestSetCache(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	r, _ := NewRRule(ROption{Freq: Daily, Count: 5, Dtstart: dtstart})
+	set := Set{}
+	set.RRule(r)
+	set.EnableCache(100)
+	assert.Equal(t, r.All(), set.All())
+	assert.Len(t, set.cache.times, 5)
+
+	set.ExDate(dtstart.AddDate(0, 0, 1))
+	assert.Empty(t, set.cache.times)
+	set.RDate(dtstart.AddDate(0, 0, 10))
+	assert.Equal(t, []time.Time{
+		dtstart,
+		dtstart.AddDate(0, 0, 2),
+		dtstart.AddDate(0, 0, 3),
+		dtstart.AddDate(0, 0, 4),
+		dtstart.AddDate(0, 0, 10),
+	}, set.All())
+
+	set.SetExDates(nil)
+	set.SetRDates(nil)
+	assert.Equal(t, r.All(), set.All())
+
+	// Modifying the rule of the set empties the cache of the set.
+	set.GetRRule().Until(dtstart.AddDate(0, 0, 2))
+	assert.Equal(t, []time.Time{dtstart, dtstart.AddDate(0, 0, 1), dtstart.AddDate(0, 0, 2)}, set.All())
+	set.GetRRule().DTStart(dtstart.AddDate(0, 0, 1))
+	assert.Equal(t, []time.Time{dtstart.AddDate(0, 0, 1), dtstart.AddDate(0, 0, 2)}, set.All())
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	r, _ := NewRRule(ROption{Freq: Hourly, Count: 500, Dtstart: dtstart})
+	want := r.All()
+	r.EnableCache(200)
+	hammer(t, func() {
+		assert.Equal(t, want, r.All())
+		assert.Equal(t, want[100:301], r.Between(want[100], want[300], true))
+	})
+}
+
+func BenchmarkCachedBetween(b *testing.B) {
+	r, _ := NewRRule(ROption{Freq: Hourly, Dtstart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+	r.EnableCache(10000)
+	after := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := after.AddDate(0, 0, 7)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Between(after, before, true)
+	}
+}
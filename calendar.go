@@ -0,0 +1,233 @@
+package rrule
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxAdjustDays bounds the search for a business day, an occurrence is
+// dropped when the calendar has none within this many days.
+const maxAdjustDays = 366
+
+type civilDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func civilDateOf(t time.Time) civilDate {
+	y, m, d := t.Date()
+	return civilDate{y, m, d}
+}
+
+// Calendar defines business days as the days which are neither weekend days
+// nor holidays. Holidays are given as dates or as recurrence sets, e.g. a Set
+// with BYEASTER=-2 for Good Friday.
+// The zero Calendar has neither weekend days nor holidays. A Calendar is safe
+// for concurrent use once its holidays are added.
+type Calendar struct {
+	weekend  [7]bool
+	holidays map[civilDate]struct{}
+	sets     []*Set
+
+	mu sync.Mutex
+	// years caches the dates of the occurrences of sets by year and location.
+	years map[holidayYear]map[civilDate]struct{}
+}
+
+type holidayYear struct {
+	year int
+	loc  *time.Location
+}
+
+// NewCalendar returns a calendar with the given weekend days, Saturday and
+// Sunday when none is given.
+func NewCalendar(weekend ...Weekday) *Calendar {
+	if len(weekend) == 0 {
+		weekend = []Weekday{Saturday, Sunday}
+	}
+	c := &Calendar{holidays: map[civilDate]struct{}{}}
+	for _, wday := range weekend {
+		c.weekend[wday.weekday] = true
+	}
+	return c
+}
+
+// AddHoliday adds holiday dates, only the date in their own location matters.
+func (c *Calendar) AddHoliday(dates ...time.Time) {
+	if c.holidays == nil {
+		c.holidays = map[civilDate]struct{}{}
+	}
+	for _, date := range dates {
+		c.holidays[civilDateOf(date)] = struct{}{}
+	}
+}
+
+// AddHolidaySet adds a recurrence set whose occurrences are holidays.
+func (c *Calendar) AddHolidaySet(set *Set) {
+	c.sets = append(c.sets, set)
+	c.mu.Lock()
+	c.years = nil
+	c.mu.Unlock()
+}
+
+// IsWeekend reports whether t falls on a weekend day.
+func (c *Calendar) IsWeekend(t time.Time) bool {
+	return c.weekend[toPyWeekday(t.Weekday())]
+}
+
+// IsHoliday reports whether the date of t is a holiday.
+func (c *Calendar) IsHoliday(t time.Time) bool {
+	if _, ok := c.holidays[civilDateOf(t)]; ok {
+		return true
+	}
+	if len(c.sets) == 0 {
+		return false
+	}
+	_, ok := c.setHolidays(t.Year(), t.Location())[civilDateOf(t)]
+	return ok
+}
+
+// setHolidays returns the dates in loc of the occurrences of the holiday sets
+// in year, computed once per year and location.
+func (c *Calendar) setHolidays(year int, loc *time.Location) map[civilDate]struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := holidayYear{year, loc}
+	if dates, ok := c.years[key]; ok {
+		return dates
+	}
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	end := start.AddDate(1, 0, 0)
+	dates := map[civilDate]struct{}{}
+	for _, set := range c.sets {
+		for _, dt := range set.Between(start, end, true) {
+			if dt.Before(end) {
+				dates[civilDateOf(dt.In(loc))] = struct{}{}
+			}
+		}
+	}
+	if c.years == nil {
+		c.years = map[holidayYear]map[civilDate]struct{}{}
+	}
+	c.years[key] = dates
+	return dates
+}
+
+// IsBusinessDay reports whether t falls on a business day.
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	return !c.IsWeekend(t) && !c.IsHoliday(t)
+}
+
+// Adjust moves t to a business day keeping its clock time, ok is false when
+// there is no business day within a year.
+func (c *Calendar) Adjust(t time.Time, adjustment Adjustment) (time.Time, bool) {
+	if c.IsBusinessDay(t) {
+		return t, true
+	}
+	y, m, d := t.Date()
+	hour, min, sec := t.Clock()
+	shift := func(days int) time.Time {
+		return time.Date(y, m, d+days, hour, min, sec, t.Nanosecond(), t.Location())
+	}
+	for i := 1; i <= maxAdjustDays; i++ {
+		switch adjustment {
+		case Following:
+			if dt := shift(i); c.IsBusinessDay(dt) {
+				return dt, true
+			}
+		case Preceding:
+			if dt := shift(-i); c.IsBusinessDay(dt) {
+				return dt, true
+			}
+		case Nearest:
+			if dt := shift(i); c.IsBusinessDay(dt) {
+				return dt, true
+			}
+			if dt := shift(-i); c.IsBusinessDay(dt) {
+				return dt, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// BusinessDayRule wraps a rule so that occurrences falling on a non business
+// day of a Calendar are adjusted to a business day. Occurrences adjusted onto
+// the same time are generated once.
+type BusinessDayRule struct {
+	rrule      *RRule
+	calendar   *Calendar
+	adjustment Adjustment
+}
+
+// NewBusinessDayRule returns a rule adjusting the occurrences of r.
+func NewBusinessDayRule(r *RRule, calendar *Calendar, adjustment Adjustment) *BusinessDayRule {
+	return &BusinessDayRule{rrule: r, calendar: calendar, adjustment: adjustment}
+}
+
+// Iterator returns an iterator for BusinessDayRule.
+func (b *BusinessDayRule) Iterator() Next {
+	next := b.rrule.Iterator()
+	// Adjustments are monotonic on dates, so an occurrence adjusted later
+	// cannot precede the adjusted date of the last one.
+	var pending []time.Time
+	var bound, last time.Time
+	done := false
+	return func() (time.Time, bool) {
+		for {
+			if len(pending) != 0 && (done || pending[0].Before(bound)) {
+				dt := pending[0]
+				pending = pending[1:]
+				if !last.IsZero() && !dt.After(last) {
+					continue
+				}
+				last = dt
+				return dt, true
+			}
+			if done {
+				return time.Time{}, false
+			}
+			raw, ok := next()
+			if !ok {
+				done = true
+				continue
+			}
+			dt, ok := b.calendar.Adjust(raw, b.adjustment)
+			if !ok {
+				continue
+			}
+			i := sort.Search(len(pending), func(i int) bool { return pending[i].After(dt) })
+			pending = append(pending, time.Time{})
+			copy(pending[i+1:], pending[i:])
+			pending[i] = dt
+			y, m, d := dt.Date()
+			bound = time.Date(y, m, d, 0, 0, 0, 0, dt.Location())
+		}
+	}
+}
+
+// All returns all occurrences of the BusinessDayRule.
+func (b *BusinessDayRule) All() []time.Time {
+	return all(b.Iterator())
+}
+
+// Between returns all the occurrences of the BusinessDayRule between after and before.
+// The inc keyword defines what happens if after and/or before are themselves occurrences.
+// With inc == True, they will be included in the list, if they are found in the recurrence set.
+func (b *BusinessDayRule) Between(after, before time.Time, inc bool) []time.Time {
+	return between(b.Iterator(), after, before, inc)
+}
+
+// Before returns the last adjusted occurrence before the given datetime instance,
+// or time.Time's zero value if no occurrence match.
+func (b *BusinessDayRule) Before(dt time.Time, inc bool) time.Time {
+	return before(b.Iterator(), dt, inc)
+}
+
+// After returns the first adjusted occurrence after the given datetime instance,
+// or time.Time's zero value if no occurrence match.
+func (b *BusinessDayRule) After(dt time.Time, inc bool) time.Time {
+	return after(b.Iterator(), dt, inc)
+}
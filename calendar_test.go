@@ -0,0 +1,141 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarAdjust(t *testing.T) {
+	t.Parallel()
+	cal := NewCalendar()
+	cal.AddHoliday(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	goodFriday, _ := NewRRule(ROption{Freq: Yearly, Byeaster: []int{-2},
+		Dtstart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+	set := &Set{}
+	set.RRule(goodFriday)
+	cal.AddHolidaySet(set)
+
+	assert.True(t, cal.IsHoliday(time.Date(2024, 3, 29, 15, 0, 0, 0, time.UTC)))
+	assert.False(t, cal.IsBusinessDay(time.Date(2024, 3, 30, 9, 0, 0, 0, time.UTC)))
+	assert.True(t, cal.IsBusinessDay(time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)))
+
+	sat := time.Date(2024, 3, 30, 9, 0, 0, 0, time.UTC)
+	for adjustment, want := range map[Adjustment]time.Time{
+		Following: time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC),
+		Preceding: time.Date(2024, 3, 28, 9, 0, 0, 0, time.UTC),
+		Nearest:   time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC),
+	} {
+		dt, ok := cal.Adjust(sat, adjustment)
+		assert.True(t, ok)
+		assert.Equal(t, want, dt, adjustment.String())
+	}
+
+	_, ok := NewCalendar(Monday, Tuesday, Wednesday, Thursday, Friday, Saturday, Sunday).Adjust(sat, Following)
+	assert.False(t, ok)
+}
+
+func TestCalendarHoliday(t *testing.T) {
+	t.Parallel()
+	var cal Calendar
+	cal.AddHoliday(time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC))
+	assert.True(t, cal.IsHoliday(time.Date(2024, 12, 25, 18, 0, 0, 0, time.UTC)))
+	assert.False(t, cal.IsHoliday(time.Date(2025, 4, 18, 9, 0, 0, 0, time.UTC)))
+
+	// Sets added after a query are seen, in every year and location.
+	goodFriday, _ := NewRRule(ROption{Freq: Yearly, Byeaster: []int{-2}, Byhour: []int{23},
+		Dtstart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)})
+	set := &Set{}
+	set.RRule(goodFriday)
+	cal.AddHolidaySet(set)
+	assert.True(t, cal.IsHoliday(time.Date(2024, 3, 29, 9, 0, 0, 0, time.UTC)))
+	assert.True(t, cal.IsHoliday(time.Date(2025, 4, 18, 9, 0, 0, 0, time.UTC)))
+	assert.False(t, cal.IsHoliday(time.Date(2025, 4, 19, 9, 0, 0, 0, time.UTC)))
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	assert.True(t, cal.IsHoliday(time.Date(2025, 4, 19, 9, 0, 0, 0, tokyo)))
+	assert.False(t, cal.IsHoliday(time.Date(2025, 4, 18, 9, 0, 0, 0, tokyo)))
+}
+
+func TestBusinessDayRule(t *testing.T) {
+	t.Parallel()
+	cal := NewCalendar()
+	cal.AddHoliday(time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC))
+	r, _ := NewRRule(ROption{Freq: Monthly, Count: 6, Bymonthday: []int{15},
+		Dtstart: time.Date(2024, 4, 15, 10, 0, 0, 0, time.UTC)})
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 4, 15, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 17, 10, 0, 0, 0, time.UTC), // Saturday
+		time.Date(2024, 7, 16, 10, 0, 0, 0, time.UTC), // holiday
+		time.Date(2024, 8, 15, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 9, 16, 10, 0, 0, 0, time.UTC), // Sunday
+	}, NewBusinessDayRule(r, cal, Following).All())
+
+	// Weekend days collide with Monday and Friday.
+	r, _ = NewRRule(ROption{Freq: Daily, Count: 7,
+		Dtstart: time.Date(2024, 6, 14, 10, 0, 0, 0, time.UTC)})
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 6, 14, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 17, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 18, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 19, 10, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 20, 10, 0, 0, 0, time.UTC),
+	}, NewBusinessDayRule(r, cal, Nearest).All())
+
+	// Adjusted occurrences stay in order with those of the business day.
+	r, _ = NewRRule(ROption{Freq: Hourly, Interval: 12, Count: 6,
+		Dtstart: time.Date(2024, 6, 15, 20, 0, 0, 0, time.UTC)})
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 6, 17, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 17, 20, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 18, 8, 0, 0, 0, time.UTC),
+	}, NewBusinessDayRule(r, cal, Following).All())
+}
+
+func TestBybusinessday(t *testing.T) {
+	t.Parallel()
+	cal := NewCalendar()
+	cal.AddHoliday(time.Date(2024, 6, 4, 0, 0, 0, 0, time.UTC))
+	r, err := NewRRule(ROption{Freq: Monthly, Count: 4, Bybusinessday: []int{3, -1},
+		BusinessCalendar: cal, Dtstart: time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 6, 6, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 28, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 7, 3, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 7, 31, 9, 0, 0, 0, time.UTC),
+	}, r.All())
+
+	_, err = NewRRule(ROption{Freq: Hourly, Bybusinessday: []int{1}})
+	assert.ErrorIs(t, err, ErrInvalidFreq)
+	_, err = NewRRule(ROption{Freq: Monthly, Bybusinessday: []int{1}, Bysetpos: []int{1}})
+	assert.ErrorIs(t, err, ErrInvalidRRuleFormat)
+	_, err = NewRRule(ROption{Freq: Monthly, Bybusinessday: []int{0}})
+	assert.ErrorIs(t, err, ErrInvalidateBound)
+}
+
+func TestBybusinessdayStr(t *testing.T) {
+	t.Parallel()
+	str := "DTSTART:20240601T090000Z\nX-BUSINESSDAY-RRULE:FREQ=MONTHLY;COUNT=2;BYBUSINESSDAY=3"
+	set, err := StrToRRuleSet(str)
+	assert.NoError(t, err)
+	assert.Equal(t, str, set.String())
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 6, 5, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 7, 3, 9, 0, 0, 0, time.UTC),
+	}, set.All())
+
+	cal := NewCalendar()
+	cal.AddHoliday(time.Date(2024, 6, 4, 0, 0, 0, 0, time.UTC))
+	set.GetRRule().BusinessCalendar(cal)
+	assert.Equal(t, time.Date(2024, 6, 6, 9, 0, 0, 0, time.UTC), set.All()[0])
+
+	_, err = StrToRRuleSet("DTSTART:20240601T090000Z\nRRULE:FREQ=MONTHLY;BYBUSINESSDAY=3")
+	assert.ErrorIs(t, err, ErrInvalidRRuleFormat)
+	_, err = StrToRRule("DTSTART:20240601T090000Z\nRRULE:FREQ=MONTHLY;BYBUSINESSDAY=3")
+	assert.ErrorIs(t, err, ErrInvalidRRuleFormat)
+	r, err := StrToRRule("DTSTART:20240601T090000Z\nX-BUSINESSDAY-RRULE:FREQ=MONTHLY;COUNT=2;BYBUSINESSDAY=3")
+	assert.NoError(t, err)
+	assert.Equal(t, set.GetRRule().String(), r.String())
+}
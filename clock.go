@@ -0,0 +1,48 @@
+package rrule
+
+import "time"
+
+// Clock tells the current time and makes timers, so that the default DTSTART
+// and the scheduling can be tested without waiting, see the rruletest
+// package for a fake implementation.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer sends the current time on its channel once its duration elapsed,
+// unless it is stopped first.
+type Timer interface {
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, it reports whether it did.
+	Stop() bool
+}
+
+// SystemClock is the Clock of the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// clockOrSystem returns clock, the SystemClock when it is nil.
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock
+	}
+	return clock
+}
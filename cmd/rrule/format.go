@@ -0,0 +1,230 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kiraxie/rrule-go"
+)
+
+// pgTimeFormat is the text of a timestamp without time zone in the JSON and
+// the composite values of postgres-rrule.
+const pgTimeFormat = "2006-01-02T15:04:05"
+
+// jsonRRule is the JSON of the postgres-rrule rrule_to_jsonb function.
+type jsonRRule struct {
+	Freq       string   `json:"freq"`
+	Interval   int      `json:"interval,omitempty"`
+	Count      int      `json:"count,omitempty"`
+	Until      string   `json:"until,omitempty"`
+	Bysecond   []int    `json:"bysecond,omitempty"`
+	Byminute   []int    `json:"byminute,omitempty"`
+	Byhour     []int    `json:"byhour,omitempty"`
+	Byday      []string `json:"byday,omitempty"`
+	Bymonthday []int    `json:"bymonthday,omitempty"`
+	Byyearday  []int    `json:"byyearday,omitempty"`
+	Byweekno   []int    `json:"byweekno,omitempty"`
+	Bymonth    []int    `json:"bymonth,omitempty"`
+	Bysetpos   []int    `json:"bysetpos,omitempty"`
+	Wkst       string   `json:"wkst,omitempty"`
+	Byeaster   []int    `json:"byeaster,omitempty"`
+}
+
+// jsonRRuleSet is the JSON of the postgres-rrule rruleset_to_jsonb function,
+// its exrule and dtend are not supported.
+type jsonRRuleSet struct {
+	Dtstart string     `json:"dtstart,omitempty"`
+	Dtend   string     `json:"dtend,omitempty"`
+	RRule   *jsonRRule `json:"rrule,omitempty"`
+	Exrule  *jsonRRule `json:"exrule,omitempty"`
+	RDate   []string   `json:"rdate,omitempty"`
+	ExDate  []string   `json:"exdate,omitempty"`
+}
+
+// parseJSON parses a rruleset, or a single rrule object.
+func parseJSON(input string, loc *time.Location) (*rrule.Set, error) {
+	var v jsonRRuleSet
+	if err := json.Unmarshal([]byte(input), &v); err != nil {
+		return nil, err
+	}
+	if v.RRule == nil && strings.Contains(input, `"freq"`) {
+		v = jsonRRuleSet{RRule: &jsonRRule{}}
+		if err := json.Unmarshal([]byte(input), v.RRule); err != nil {
+			return nil, err
+		}
+	}
+	if v.Dtend != "" || v.Exrule != nil {
+		return nil, fmt.Errorf("%w: dtend and exrule are not supported", rrule.ErrInvalidRRuleFormat)
+	}
+
+	set := &rrule.Set{}
+	dtstart, err := parseTime(v.Dtstart, loc)
+	if err != nil {
+		return nil, err
+	}
+	if !dtstart.IsZero() {
+		set.DTStart(dtstart)
+	}
+	if v.RRule != nil {
+		option, err := rrule.StrToROptionInLocation(v.RRule.rfcString(), loc)
+		if err != nil {
+			return nil, err
+		}
+		option.Dtstart = dtstart
+		r, err := rrule.NewRRule(*option)
+		if err != nil {
+			return nil, err
+		}
+		set.RRule(r)
+	}
+	for _, s := range v.RDate {
+		dt, err := parseTime(s, loc)
+		if err != nil {
+			return nil, err
+		}
+		set.RDate(dt)
+	}
+	for _, s := range v.ExDate {
+		dt, err := parseTime(s, loc)
+		if err != nil {
+			return nil, err
+		}
+		set.ExDate(dt)
+	}
+	return set, nil
+}
+
+// rfcString returns the rule as the value of an RRULE property, so that it
+// is validated by the parser of the package.
+func (r *jsonRRule) rfcString() string {
+	parts := []string{"FREQ=" + strings.ToUpper(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if r.Count != 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	if r.Until != "" {
+		until := strings.NewReplacer("-", "", ":", "", " ", "T").Replace(r.Until)
+		parts = append(parts, "UNTIL="+until)
+	}
+	if r.Wkst != "" {
+		parts = append(parts, "WKST="+r.Wkst)
+	}
+	for _, p := range []struct {
+		name   string
+		values []int
+	}{
+		{"BYSETPOS", r.Bysetpos},
+		{"BYMONTH", r.Bymonth},
+		{"BYMONTHDAY", r.Bymonthday},
+		{"BYYEARDAY", r.Byyearday},
+		{"BYWEEKNO", r.Byweekno},
+		{"BYHOUR", r.Byhour},
+		{"BYMINUTE", r.Byminute},
+		{"BYSECOND", r.Bysecond},
+		{"BYEASTER", r.Byeaster},
+	} {
+		if len(p.values) != 0 {
+			parts = append(parts, p.name+"="+joinInts(p.values))
+		}
+	}
+	if len(r.Byday) != 0 {
+		parts = append(parts, "BYDAY="+strings.Join(r.Byday, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+func joinInts(values []int) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ",")
+}
+
+// formatJSONSet formats set as a rruleset, the times in their location.
+func formatJSONSet(set *rrule.Set) (string, error) {
+	v := jsonRRuleSet{}
+	if dt := set.GetDTStart(); !dt.IsZero() {
+		v.Dtstart = dt.Format(pgTimeFormat)
+	}
+	if r := set.GetRRule(); r != nil {
+		option := r.OrigOptions
+		if option.Rscale != "" || len(option.Bybusinessday) != 0 || len(option.Byleapmonth) != 0 {
+			return "", fmt.Errorf("%w: RSCALE and BYBUSINESSDAY have no JSON", rrule.ErrNotRepresentable)
+		}
+		v.RRule = &jsonRRule{
+			Freq:       option.Freq.String(),
+			Interval:   option.Interval,
+			Count:      option.Count,
+			Bysecond:   option.Bysecond,
+			Byminute:   option.Byminute,
+			Byhour:     option.Byhour,
+			Bymonthday: option.Bymonthday,
+			Byyearday:  option.Byyearday,
+			Byweekno:   option.Byweekno,
+			Bymonth:    option.Bymonth,
+			Bysetpos:   option.Bysetpos,
+			Byeaster:   option.Byeaster,
+		}
+		if v.RRule.Interval == 0 {
+			v.RRule.Interval = 1
+		}
+		if !option.Until.IsZero() {
+			v.RRule.Until = option.Until.Format(pgTimeFormat)
+		}
+		if option.Wkst != rrule.Monday {
+			v.RRule.Wkst = option.Wkst.String()
+		}
+		for _, wday := range option.Byweekday {
+			v.RRule.Byday = append(v.RRule.Byday, wday.String())
+		}
+	}
+	for _, dt := range set.GetRDate() {
+		v.RDate = append(v.RDate, dt.Format(pgTimeFormat))
+	}
+	for _, dt := range set.GetExDate() {
+		v.ExDate = append(v.ExDate, dt.Format(pgTimeFormat))
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// parsePG parses the text of a _rrule.RRULE or _rrule.RRULESET value.
+func parsePG(input string) (*rrule.Set, error) {
+	var r rrule.RRule
+	err := r.Scan(input)
+	if err == nil {
+		set := &rrule.Set{}
+		set.RRule(&r)
+		return set, nil
+	}
+	set := &rrule.Set{}
+	if set.Scan(input) != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// formatPGSet formats set as a _rrule.RRULE value when it is a single rule,
+// as a _rrule.RRULESET value otherwise.
+func formatPGSet(set *rrule.Set) (string, error) {
+	var (
+		v   driver.Value
+		err error
+	)
+	if r := set.GetRRule(); r != nil && len(set.GetRDate()) == 0 && len(set.GetExDate()) == 0 {
+		v, err = r.Value()
+	} else {
+		v, err = set.Value()
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprint(v), nil
+}
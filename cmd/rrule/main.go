@@ -0,0 +1,224 @@
+// Command rrule validates, expands and converts recurrence rules.
+//
+// The rule is read from the arguments, one line per argument, or from the
+// standard input. It may be given as RFC 5545 text, as the JSON of the
+// postgres-rrule rruleset_to_jsonb function or as the text of a _rrule.RRULE
+// or _rrule.RRULESET composite value, e.g.
+//
+//	rrule -n 5 'DTSTART:20240101T090000Z' 'RRULE:FREQ=WEEKLY;BYDAY=MO,FR'
+//	rrule -after 2024-03-01T00:00:00Z -before 2024-04-01T00:00:00Z < rule.txt
+//	rrule -to json 'DTSTART:20240101T090000Z' 'RRULE:FREQ=DAILY;COUNT=3'
+//	rrule -to text -dtstart 2024-01-01T09:00:00Z '(WEEKLY,2,,,,,,"{MO,FR}",,,,,,MO)'
+//
+// Without -to, the occurrences are printed in RFC 3339, the first -n ones or
+// those in the window of -after and -before. An invalid rule is reported on
+// the standard error with the exit status 1.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kiraxie/rrule-go"
+)
+
+// Input and output formats.
+const (
+	formatAuto = "auto"
+	formatRFC  = "rfc"
+	formatJSON = "json"
+	formatPG   = "pg"
+	formatCron = "cron"
+	formatText = "text"
+)
+
+// defaultCount is the number of occurrences printed without -n or window.
+const defaultCount = 10
+
+var errUsage = errors.New("usage")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, errUsage) && !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "rrule:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("rrule", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: rrule [flags] [rule lines...]")
+		flags.PrintDefaults()
+	}
+	var (
+		from   = flags.String("from", formatAuto, "input format: auto, rfc, json or pg")
+		to     = flags.String("to", "", "convert to rfc, json, pg, cron or text instead of expanding")
+		count  = flags.Int("n", 0, fmt.Sprintf("number of occurrences to print, %d without a window", defaultCount))
+		after  = flags.String("after", "", "print the occurrences from this RFC 3339 time")
+		before = flags.String("before", "", "print the occurrences up to this RFC 3339 time")
+		tz     = flags.String("tz", "UTC", "time zone of the times without one")
+		start  = flags.String("dtstart", "", "DTSTART of the rule, e.g. for a composite value which has none")
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return err
+	}
+
+	input := strings.Join(flags.Args(), "\n")
+	if flags.NArg() == 0 {
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		input = string(b)
+	}
+	input = strings.TrimSpace(input)
+	if input == "" {
+		flags.Usage()
+		return errUsage
+	}
+	set, err := parse(input, *from, loc)
+	if err != nil {
+		return err
+	}
+	dtstart, err := parseTime(*start, loc)
+	if err != nil {
+		return err
+	}
+	if !dtstart.IsZero() {
+		set.DTStart(dtstart)
+	}
+
+	if *to != "" {
+		out, err := convert(set, *to)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(stdout, out)
+		return err
+	}
+	window, err := parseWindow(*after, *before, loc)
+	if err != nil {
+		return err
+	}
+	return expand(stdout, set, window, *count)
+}
+
+// parse reads input in the format, detecting it when it is formatAuto.
+func parse(input, format string, loc *time.Location) (*rrule.Set, error) {
+	if format == formatAuto {
+		switch input[0] {
+		case '{':
+			format = formatJSON
+		case '(':
+			format = formatPG
+		default:
+			format = formatRFC
+		}
+	}
+	switch format {
+	case formatRFC:
+		return rrule.StrSliceToRRuleSetInLoc(strings.Split(input, "\n"), loc)
+	case formatJSON:
+		return parseJSON(input, loc)
+	case formatPG:
+		return parsePG(input)
+	default:
+		return nil, fmt.Errorf("unknown input format %q", format)
+	}
+}
+
+// convert formats set in the format.
+func convert(set *rrule.Set, format string) (string, error) {
+	switch format {
+	case formatRFC:
+		return set.String(), nil
+	case formatJSON:
+		return formatJSONSet(set)
+	case formatPG:
+		return formatPGSet(set)
+	case formatCron:
+		r := set.GetRRule()
+		if r == nil || len(set.GetRDate()) != 0 || len(set.GetExDate()) != 0 {
+			return "", fmt.Errorf("%w: only a single RRULE converts to cron", rrule.ErrNotRepresentable)
+		}
+		option := r.OrigOptions
+		option.Dtstart = r.GetDTStart()
+		return option.CronString()
+	case formatText:
+		return describeSet(set), nil
+	default:
+		return "", fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+type window struct {
+	after, before time.Time
+}
+
+func parseWindow(after, before string, loc *time.Location) (w window, err error) {
+	if w.after, err = parseTime(after, loc); err != nil {
+		return
+	}
+	if w.before, err = parseTime(before, loc); err != nil {
+		return
+	}
+	if !w.after.IsZero() && !w.before.IsZero() && w.before.Before(w.after) {
+		err = fmt.Errorf("-before %s is earlier than -after %s", before, after)
+	}
+	return
+}
+
+// parseTime parses an RFC 3339 time, or a local time in loc without offset.
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", time.DateTime, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// expand prints the occurrences of set within win inclusively, at most count
+// of them when count is positive. Without window, count defaults to
+// defaultCount.
+func expand(w io.Writer, set *rrule.Set, win window, count int) error {
+	if count <= 0 && win.after.IsZero() && win.before.IsZero() {
+		count = defaultCount
+	}
+	if count <= 0 && win.before.IsZero() && !set.IsFinite() {
+		return fmt.Errorf("%w: give -n or -before", rrule.ErrInfinite)
+	}
+	next := set.Iterator()
+	for printed := 0; count <= 0 || printed < count; {
+		dt, ok := next()
+		if !ok || !win.before.IsZero() && dt.After(win.before) {
+			return nil
+		}
+		if dt.Before(win.after) {
+			continue
+		}
+		if _, err := fmt.Fprintln(w, dt.Format(time.RFC3339)); err != nil {
+			return err
+		}
+		printed++
+	}
+	return nil
+}
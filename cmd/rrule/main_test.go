@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiraxie/rrule-go"
+)
+
+func runCLI(stdin string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func TestExpand(t *testing.T) {
+	t.Parallel()
+	out, err := runCLI("", "-n", "3", "DTSTART:20240101T090000Z", "RRULE:FREQ=WEEKLY;BYDAY=MO,FR")
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-01T09:00:00Z\n2024-01-05T09:00:00Z\n2024-01-08T09:00:00Z\n", out)
+
+	out, err = runCLI("DTSTART:20240101T090000Z\nRRULE:FREQ=DAILY\nEXDATE:20240106T090000Z\n",
+		"-after", "2024-01-05T00:00:00Z", "-before", "2024-01-07T09:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-05T09:00:00Z\n2024-01-07T09:00:00Z\n", out)
+
+	_, err = runCLI("", "-after", "2024-01-05T00:00:00Z", "DTSTART:20240101T090000Z", "RRULE:FREQ=DAILY")
+	assert.ErrorIs(t, err, rrule.ErrInfinite)
+	_, err = runCLI("", "RRULE:FREQ=DAILY;BYHOUR=25")
+	assert.ErrorIs(t, err, rrule.ErrInvalidateBound)
+}
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+	rfc := "DTSTART:20240101T090000Z\nRRULE:FREQ=MONTHLY;COUNT=3;BYDAY=-1FR\nEXDATE:20240126T090000Z"
+	json := `{"dtstart":"2024-01-01T09:00:00","rrule":{"freq":"MONTHLY","interval":1,"count":3,"byday":["-1FR"]},"exdate":["2024-01-26T09:00:00"]}`
+	out, err := runCLI(rfc, "-to", "json")
+	assert.NoError(t, err)
+	assert.Equal(t, json+"\n", out)
+	out, err = runCLI(json, "-to", "rfc")
+	assert.NoError(t, err)
+	assert.Equal(t, rfc+"\n", out)
+	out, err = runCLI(`{"freq":"DAILY","count":2}`, "-dtstart", "2024-01-01T09:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-01T09:00:00Z\n2024-01-02T09:00:00Z\n", out)
+
+	pg := `(WEEKLY,2,,,,,,"{MO,FR}",,,,,,MO,)`
+	out, err = runCLI("", "-to", "pg", "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR")
+	assert.NoError(t, err)
+	assert.Equal(t, pg+"\n", out)
+	out, err = runCLI(`(WEEKLY,1,,,,,,"{MO,FR}",,,,,,MO)`, "-dtstart", "2024-01-01T09:30:00Z", "-to", "cron")
+	assert.NoError(t, err)
+	assert.Equal(t, "30 9 * * 1,5\n", out)
+	_, err = runCLI(pg, "-to", "cron")
+	assert.ErrorIs(t, err, rrule.ErrNotRepresentable)
+	out, err = runCLI("", "-to", "pg", "DTSTART:20240101T090000Z", "RRULE:FREQ=WEEKLY;COUNT=3;BYDAY=FR", "EXDATE:20240112T090000Z")
+	assert.NoError(t, err)
+	assert.Equal(t, `("2024-01-01 09:00:00",,"(WEEKLY,1,3,,,,,""{FR}"",,,,,,MO,)",,,"{""2024-01-12 09:00:00""}")`+"\n", out)
+	out, err = runCLI(out)
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-05T09:00:00Z\n2024-01-19T09:00:00Z\n", out)
+	_, err = runCLI(pg, "-from", "json")
+	assert.Error(t, err)
+}
+
+func TestDescribe(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		rule string
+		want string
+	}{
+		{
+			"DTSTART:20240101T090000Z\nRRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=10",
+			"every 2 weeks on Monday and Friday at 09:00, starting 2024-01-01 09:00:00 UTC, 10 times",
+		},
+		{
+			"DTSTART;TZID=Europe/Paris:20240101T090000\nRRULE:FREQ=MONTHLY;BYMONTH=1,6;BYMONTHDAY=1,-1;UNTIL=20250101T000000Z",
+			"every month on the 1st and last day of the month in January and June at 09:00, starting 2024-01-01 09:00:00 CET, until 2025-01-01 00:00:00 UTC",
+		},
+		{
+			"DTSTART:20240101T000000Z\nRRULE:FREQ=YEARLY;BYDAY=-2SU,+3TH;BYMONTH=11\nRDATE:20240704T000000Z",
+			"every year on the 2nd to last Sunday and the 3rd Thursday in November at 00:00, starting 2024-01-01 00:00:00 UTC, plus 1 date",
+		},
+		{
+			"DTSTART:20240101T000000Z\nRRULE:FREQ=MINUTELY;INTERVAL=15;BYHOUR=9,10,11,12",
+			"every 15 minutes at hour 9, 10, 11 and 12, at second 0, starting 2024-01-01 00:00:00 UTC",
+		},
+	}
+	for _, c := range cases {
+		out, err := runCLI(c.rule, "-to", "text")
+		assert.NoError(t, err, c.rule)
+		assert.Equal(t, c.want+"\n", out)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kiraxie/rrule-go"
+)
+
+var (
+	frequencyUnits = map[rrule.Frequency]string{
+		rrule.Yearly:   "year",
+		rrule.Monthly:  "month",
+		rrule.Weekly:   "week",
+		rrule.Daily:    "day",
+		rrule.Hourly:   "hour",
+		rrule.Minutely: "minute",
+		rrule.Secondly: "second",
+	}
+	weekdayNames = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+)
+
+// describeSet renders set as English text, e.g. "every 2 weeks on Monday
+// and Friday at 09:00, starting 2024-01-01 09:00:00 UTC, 10 times".
+func describeSet(set *rrule.Set) string {
+	var parts []string
+	if r := set.GetRRule(); r != nil {
+		parts = append(parts, describe(r))
+	}
+	if n := len(set.GetRDate()); n != 0 {
+		parts = append(parts, fmt.Sprintf("plus %s", plural(n, "date")))
+	}
+	if n := len(set.GetExDate()); n != 0 {
+		parts = append(parts, fmt.Sprintf("except %s", plural(n, "date")))
+	}
+	if len(parts) == 0 {
+		return "never"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describe renders the options of r, completed with the defaults taken
+// from its DTSTART.
+func describe(r *rrule.RRule) string {
+	option := r.Options
+	var b strings.Builder
+	b.WriteString("every ")
+	if option.Interval > 1 {
+		b.WriteString(plural(option.Interval, frequencyUnits[option.Freq]))
+	} else {
+		b.WriteString(frequencyUnits[option.Freq])
+	}
+	if option.Rscale != "" {
+		fmt.Fprintf(&b, " of the %s calendar", strings.ToLower(option.Rscale))
+	}
+
+	if len(option.Byweekno) != 0 {
+		fmt.Fprintf(&b, " in week %s", list(option.Byweekno, strconv.Itoa))
+	}
+	if len(option.Byyearday) != 0 {
+		fmt.Fprintf(&b, " on the %s day of the year", list(option.Byyearday, ordinal))
+	}
+	if len(option.Bymonthday) != 0 {
+		fmt.Fprintf(&b, " on the %s day of the month", list(option.Bymonthday, ordinal))
+	}
+	if len(option.Byweekday) != 0 {
+		days := make([]string, len(option.Byweekday))
+		for i, wday := range option.Byweekday {
+			days[i] = weekdayNames[wday.Day()]
+			if wday.N() != 0 {
+				days[i] = "the " + ordinal(wday.N()) + " " + days[i]
+			}
+		}
+		fmt.Fprintf(&b, " on %s", join(days))
+	}
+	if len(option.Byeaster) != 0 {
+		fmt.Fprintf(&b, " %s", list(option.Byeaster, easterOffset))
+	}
+	if len(option.Bybusinessday) != 0 {
+		fmt.Fprintf(&b, " on the %s business day", list(option.Bybusinessday, ordinal))
+	}
+	if len(option.Bymonth) != 0 || len(option.Byleapmonth) != 0 {
+		months := make([]string, 0, len(option.Bymonth)+len(option.Byleapmonth))
+		for _, month := range option.Bymonth {
+			months = append(months, monthName(option, month))
+		}
+		for _, month := range option.Byleapmonth {
+			months = append(months, "leap month "+strconv.Itoa(month))
+		}
+		fmt.Fprintf(&b, " in %s", join(months))
+	}
+	if len(option.Bysetpos) != 0 {
+		fmt.Fprintf(&b, ", only the %s of each %s", list(option.Bysetpos, ordinal), frequencyUnits[option.Freq])
+	}
+	b.WriteString(describeTime(option))
+
+	fmt.Fprintf(&b, ", starting %s", option.Dtstart.Format("2006-01-02 15:04:05 MST"))
+	switch {
+	case option.Count != 0:
+		fmt.Fprintf(&b, ", %s", plural(option.Count, "time"))
+	case !option.Until.IsZero():
+		fmt.Fprintf(&b, ", until %s", option.Until.Format("2006-01-02 15:04:05 MST"))
+	}
+	return b.String()
+}
+
+// describeTime renders the times of day of the rule, as a list of times
+// when there are few of them.
+func describeTime(option rrule.ROption) string {
+	hours, minutes, seconds := option.Byhour, option.Byminute, option.Bysecond
+	if len(hours) == 0 && option.Freq < rrule.Hourly {
+		hours = []int{option.Dtstart.Hour()}
+	}
+	if len(minutes) == 0 && option.Freq < rrule.Minutely {
+		minutes = []int{option.Dtstart.Minute()}
+	}
+	if len(seconds) == 0 && option.Freq < rrule.Secondly {
+		seconds = []int{option.Dtstart.Second()}
+	}
+	if len(hours) != 0 && len(minutes) != 0 && len(seconds) != 0 && len(hours)*len(minutes)*len(seconds) <= 6 {
+		var times []string
+		for _, hour := range hours {
+			for _, minute := range minutes {
+				for _, second := range seconds {
+					s := fmt.Sprintf("%02d:%02d", hour, minute)
+					if second != 0 {
+						s += fmt.Sprintf(":%02d", second)
+					}
+					times = append(times, s)
+				}
+			}
+		}
+		return " at " + join(times)
+	}
+	var parts []string
+	for _, p := range []struct {
+		name   string
+		values []int
+	}{{"hour", hours}, {"minute", minutes}, {"second", seconds}} {
+		if len(p.values) != 0 {
+			parts = append(parts, fmt.Sprintf(" at %s %s", p.name, list(p.values, strconv.Itoa)))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func monthName(option rrule.ROption, month int) string {
+	if option.Rscale != "" || month < 1 || month > 12 {
+		return "month " + strconv.Itoa(month)
+	}
+	return time.Month(month).String()
+}
+
+func easterOffset(n int) string {
+	switch {
+	case n == 0:
+		return "on Easter Sunday"
+	case n < 0:
+		return plural(-n, "day") + " before Easter"
+	default:
+		return plural(n, "day") + " after Easter"
+	}
+}
+
+// ordinal renders n as 1st, 2nd, ..., negative numbers counting from the
+// end, e.g. "last" and "2nd to last".
+func ordinal(n int) string {
+	switch {
+	case n == -1:
+		return "last"
+	case n < 0:
+		return ordinal(-n) + " to last"
+	}
+	suffix := "th"
+	switch n % 10 {
+	case 1:
+		suffix = "st"
+	case 2:
+		suffix = "nd"
+	case 3:
+		suffix = "rd"
+	}
+	if n%100 >= 11 && n%100 <= 13 {
+		suffix = "th"
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+func list(values []int, format func(int) string) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = format(v)
+	}
+	return join(s)
+}
+
+// join joins words as an English list, e.g. "a, b and c".
+func join(words []string) string {
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
@@ -0,0 +1,83 @@
+package rrule
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// hammer runs f from many goroutines at once, go test -race reports any
+// shared state they modify.
+func hammer(t *testing.T, f func()) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				f()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConcurrentRRule(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	for _, option := range []ROption{
+		{Freq: Monthly, Bymonthday: []int{31}, Skip: Backward},
+		{Freq: Weekly, Byweekday: []Weekday{Monday, Friday}, Byhour: []int{9, 17}},
+		{Freq: Hourly, Interval: 5, Byweekday: []Weekday{Saturday}},
+		{Freq: Yearly, Rscale: "CHINESE", Bymonth: []int{1}, Bymonthday: []int{1}},
+		{Freq: Monthly, Bybusinessday: []int{-1}},
+	} {
+		option.Dtstart = dtstart
+		r, err := NewRRule(option)
+		assert.NoError(t, err)
+		want := r.Between(dtstart, dtstart.AddDate(5, 0, 0), true)
+		hammer(t, func() {
+			assert.Equal(t, want, r.Between(dtstart, dtstart.AddDate(5, 0, 0), true))
+			assert.True(t, r.Contains(want[3]))
+			index, _ := r.Index(want[3])
+			assert.Equal(t, 3, index)
+			assert.Equal(t, len(want), r.CountBetween(dtstart, dtstart.AddDate(5, 0, 0), true))
+			assert.Equal(t, want[0], r.WithDTStart(dtstart).After(dtstart, true))
+		})
+	}
+}
+
+func TestConcurrentSet(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	r, _ := NewRRule(ROption{Freq: Daily, Count: 100})
+	set := &Set{}
+	set.DTStart(dtstart)
+	set.RRule(r)
+	// Unordered dates are iterated without sorting the Set.
+	set.RDate(dtstart.AddDate(1, 0, 0))
+	set.RDate(dtstart.Add(time.Hour))
+	set.ExDate(dtstart.AddDate(0, 0, 50))
+	set.ExDate(dtstart.AddDate(0, 0, 2))
+	want := set.All()
+	assert.Len(t, want, 100)
+
+	cal := NewCalendar()
+	cal.AddHolidaySet(set)
+	weekly, _ := NewRRule(ROption{Freq: Weekly, Dtstart: dtstart, Count: 20})
+	adjusted := NewBusinessDayRule(weekly, cal, Nearest)
+	wantAdjusted := adjusted.All()
+	union := Union(set, weekly)
+	wantUnion := union.All()
+
+	hammer(t, func() {
+		assert.Equal(t, want, set.All())
+		assert.Equal(t, want[10], set.After(want[9], false))
+		assert.True(t, set.Contains(want[99]))
+		assert.Equal(t, wantAdjusted, adjusted.All())
+		assert.Equal(t, wantUnion, union.All())
+	})
+}
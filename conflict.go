@@ -0,0 +1,99 @@
+package rrule
+
+import (
+	"sort"
+	"time"
+)
+
+// Schedule is a recurrence source whose occurrences last Duration, each one
+// covering the half-open interval [occurrence, occurrence+Duration).
+type Schedule struct {
+	Source   Iterable
+	Duration time.Duration
+}
+
+// Conflict is an overlap between an occurrence of the candidate schedule and
+// an occurrence of an existing one.
+type Conflict struct {
+	// Index is the position of the existing schedule in the collection.
+	Index int
+	// Start is the start of the candidate occurrence.
+	Start time.Time
+	// ExistingStart is the start of the existing occurrence.
+	ExistingStart time.Time
+}
+
+// FindConflict returns the first conflict, by candidate occurrence, between
+// candidate and the existing schedules in the horizon [start, end).
+// Schedules are expanded lazily up to end only.
+func FindConflict(candidate Schedule, existing []Schedule, start, end time.Time) (Conflict, bool) {
+	conflicts := findConflicts(candidate, existing, start, end, true)
+	if len(conflicts) == 0 {
+		return Conflict{}, false
+	}
+	return conflicts[0], true
+}
+
+// FindConflicts returns all the conflicts between candidate and the existing
+// schedules in the horizon [start, end), ordered by candidate occurrence and
+// schedule.
+func FindConflicts(candidate Schedule, existing []Schedule, start, end time.Time) []Conflict {
+	return findConflicts(candidate, existing, start, end, false)
+}
+
+// occurrencesIn returns the starts of the occurrences of s overlapping the
+// horizon [start, end).
+func (s Schedule) occurrencesIn(start, end time.Time) []time.Time {
+	var result []time.Time
+	next := s.Source.Iterator()
+	for dt, ok := next(); ok && dt.Before(end); dt, ok = next() {
+		if dt.Add(s.Duration).After(start) {
+			result = append(result, dt)
+		}
+	}
+	return result
+}
+
+func findConflicts(candidate Schedule, existing []Schedule, start, end time.Time, first bool) []Conflict {
+	occurrences := candidate.occurrencesIn(start, end)
+	if len(occurrences) == 0 {
+		return nil
+	}
+	var conflicts []Conflict
+	// bound is the number of candidate occurrences still worth checking, the
+	// first conflict found so far ending the search for the others.
+	bound := len(occurrences)
+	for index, schedule := range existing {
+		next := schedule.Source.Iterator()
+		j := 0
+		for dt, ok := next(); ok && dt.Before(end) && j < bound; dt, ok = next() {
+			dtEnd := dt.Add(schedule.Duration)
+			if !dtEnd.After(start) {
+				continue
+			}
+			// Candidate occurrences ending before dt cannot overlap it nor
+			// any later occurrence of the schedule.
+			for j < bound && !occurrences[j].Add(candidate.Duration).After(dt) {
+				j++
+			}
+			for k := j; k < bound && occurrences[k].Before(dtEnd); k++ {
+				conflicts = append(conflicts, Conflict{Index: index, Start: occurrences[k], ExistingStart: dt})
+				if first {
+					bound = k
+					break
+				}
+			}
+		}
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		a, b := conflicts[i], conflicts[j]
+		if !a.Start.Equal(b.Start) {
+			return a.Start.Before(b.Start)
+		}
+		if a.Index != b.Index {
+			return a.Index < b.Index
+		}
+		return a.ExistingStart.Before(b.ExistingStart)
+	})
+	return conflicts
+}
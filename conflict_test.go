@@ -0,0 +1,46 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindConflicts(t *testing.T) {
+	t.Parallel()
+	at := func(d, h int) time.Time { return time.Date(2024, 6, d, h, 0, 0, 0, time.UTC) }
+	// Mondays 9:00-11:00.
+	weekly, _ := NewRRule(ROption{Freq: Weekly, Dtstart: at(3, 9)})
+	// Every day 12:00-13:00.
+	daily, _ := NewRRule(ROption{Freq: Daily, Dtstart: at(1, 12)})
+	// A one-off booking on Tuesday 10:00-12:00.
+	oneOff := &Set{}
+	oneOff.RDate(at(11, 10))
+	existing := []Schedule{
+		{Source: weekly, Duration: 2 * time.Hour},
+		{Source: daily, Duration: time.Hour},
+		{Source: oneOff, Duration: 2 * time.Hour},
+	}
+
+	// Every Tuesday 11:00-12:30.
+	candidate, _ := NewRRule(ROption{Freq: Weekly, Dtstart: at(4, 11)})
+	conflicts := FindConflicts(Schedule{Source: candidate, Duration: 90 * time.Minute}, existing, at(1, 0), at(19, 0))
+	assert.Equal(t, []Conflict{
+		{Index: 1, Start: at(4, 11), ExistingStart: at(4, 12)},
+		{Index: 1, Start: at(11, 11), ExistingStart: at(11, 12)},
+		{Index: 2, Start: at(11, 11), ExistingStart: at(11, 10)},
+		{Index: 1, Start: at(18, 11), ExistingStart: at(18, 12)},
+	}, conflicts)
+
+	conflict, ok := FindConflict(Schedule{Source: candidate, Duration: 90 * time.Minute}, existing, at(5, 0), at(19, 0))
+	assert.True(t, ok)
+	assert.Equal(t, Conflict{Index: 1, Start: at(11, 11), ExistingStart: at(11, 12)}, conflict)
+
+	// Adjacent occurrences do not overlap.
+	adjacent, _ := NewRRule(ROption{Freq: Weekly, Dtstart: at(3, 11)})
+	_, ok = FindConflict(Schedule{Source: adjacent, Duration: time.Hour}, existing[:1], at(1, 0), at(30, 0))
+	assert.False(t, ok)
+	_, ok = FindConflict(Schedule{Source: candidate, Duration: time.Hour}, existing, at(20, 0), at(21, 0))
+	assert.False(t, ok)
+}
@@ -25,7 +25,7 @@ func init() {
 	M29, M30, M31 = rang(-29, 0), rang(-30, 0), rang(-31, 0)
 	mask366monthDay = concat(M31, M29, M31, M30, M31, M30, M31, M31, M30, M31, M30, M31, M31[:7])
 	mask365monthDay = concat(mask366monthDay[:31], mask366monthDay[32:])
-	for i := 0; i < 55; i++ {
+	for i := 0; i < 57; i++ {
 		maskDay = append(maskDay, []int{0, 1, 2, 3, 4, 5, 6}...)
 	}
 }
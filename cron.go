@@ -0,0 +1,570 @@
+package rrule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var cronMonthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var cronWeekdayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+// cronField is a parsed cron field. all tells whether it has every value,
+// star whether it starts with "*" or is "?", which decides how cron matches
+// the days.
+type cronField struct {
+	values []int
+	all    bool
+	star   bool
+}
+
+type cronSpec struct {
+	second, minute, hour, month cronField
+	// dom holds the days of month, -1 for "L" and -(n+1) for "L-n".
+	dom cronField
+	// domW is 1 for "1W", the first weekday of the month, and -1 for "LW".
+	domW int
+	// dow holds the weekdays, 0 for Monday as Weekday.
+	dow    cronField
+	dowNth []Weekday
+	// unsupported lists the parts which no rule can represent.
+	unsupported []string
+}
+
+// CronToROption converts a cron expression into the equivalent ROption
+// starting at dtstart. The expression has 5 fields, minute hour day-of-month
+// month day-of-week, or 6 fields starting with second. Fields are lists of
+// values, names, ranges and steps, day-of-month supports "L", "L-n", "LW" and
+// "1W", and day-of-week supports "nL" and "n#k".
+// It returns ErrNotRepresentable listing the parts no rule can represent,
+// such as both day-of-month and day-of-week being restricted, which cron
+// matches either of, see CronToIterable.
+func CronToROption(spec string, dtstart time.Time) (ROption, error) {
+	options, err := cronToROptions(spec, dtstart)
+	if err != nil {
+		return ROption{}, err
+	}
+	if len(options) != 1 {
+		return ROption{}, fmt.Errorf("%w: day-of-month or day-of-week", ErrNotRepresentable)
+	}
+	return options[0], nil
+}
+
+// CronToIterable converts a cron expression into a rule, or into the Union
+// of two rules when both day-of-month and day-of-week are restricted.
+func CronToIterable(spec string, dtstart time.Time) (Iterable, error) {
+	options, err := cronToROptions(spec, dtstart)
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]Iterable, len(options))
+	for i, option := range options {
+		r, err := NewRRule(option)
+		if err != nil {
+			return nil, err
+		}
+		sources[i] = r
+	}
+	if len(sources) == 1 {
+		return sources[0], nil
+	}
+	return Union(sources...), nil
+}
+
+func cronToROptions(spec string, dtstart time.Time) ([]ROption, error) {
+	s, err := parseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+	if s.domW != 0 {
+		if len(s.second.values)*len(s.minute.values)*len(s.hour.values) != 1 {
+			s.unsupported = append(s.unsupported, "W with several times of day")
+		}
+		if (s.dom.star || s.dow.star) && !s.dow.all {
+			s.unsupported = append(s.unsupported, "W with day-of-week")
+		}
+	}
+	if len(s.unsupported) != 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotRepresentable, strings.Join(s.unsupported, ", "))
+	}
+
+	base := ROption{Dtstart: dtstart, Freq: Daily}
+	// The frequency is the unit of the finest unrestricted time field.
+	switch {
+	case s.second.all:
+		base.Freq = Secondly
+	case s.minute.all:
+		base.Freq = Minutely
+	case s.hour.all:
+		base.Freq = Hourly
+	}
+	if !s.month.all {
+		base.Bymonth = s.month.values
+	}
+
+	// Cron matches the days of both fields when either starts with "*", the
+	// days of either field otherwise.
+	var options []ROption
+	if s.dom.star || s.dow.star {
+		options = []ROption{s.dayOption(base, true, true)}
+	} else {
+		options = []ROption{s.dayOption(base, true, false), s.dayOption(base, false, true)}
+	}
+	for i := range options {
+		options[i].Byhour = s.hour.byValues(options[i].Freq, Hourly)
+		options[i].Byminute = s.minute.byValues(options[i].Freq, Minutely)
+		options[i].Bysecond = s.second.byValues(options[i].Freq, Secondly)
+	}
+	return options, nil
+}
+
+// dayOption restricts the days of base to the day-of-month field, the
+// day-of-week field or both.
+func (s *cronSpec) dayOption(base ROption, dom, dow bool) ROption {
+	option := base
+	if dom && s.domW != 0 {
+		option.Freq = Monthly
+		option.Byweekday = []Weekday{Monday, Tuesday, Wednesday, Thursday, Friday}
+		option.Bysetpos = []int{s.domW}
+	} else if dom && !s.dom.all {
+		option.Bymonthday = s.dom.values
+	}
+	if dow && !s.dow.all {
+		for _, wday := range s.dow.values {
+			option.Byweekday = append(option.Byweekday, Weekday{weekday: wday})
+		}
+		if len(s.dowNth) != 0 {
+			option.Freq = Monthly
+			option.Byweekday = append(option.Byweekday, s.dowNth...)
+		}
+	}
+	return option
+}
+
+// byValues returns the BY* values of a time field of the given unit, none
+// when the field is unrestricted and the frequency does not default them.
+func (f cronField) byValues(freq, unit Frequency) []int {
+	if f.all && freq >= unit {
+		return nil
+	}
+	return f.values
+}
+
+func parseCron(spec string) (cronSpec, error) {
+	spec = strings.TrimSpace(spec)
+	if macro, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return cronSpec{}, fmt.Errorf("%w: %d fields in %q", ErrInvalidCron, len(fields), spec)
+	}
+
+	var s cronSpec
+	var err error
+	if s.second, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return cronSpec{}, err
+	}
+	if s.minute, err = parseCronField(fields[1], 0, 59, nil); err != nil {
+		return cronSpec{}, err
+	}
+	if s.hour, err = parseCronField(fields[2], 0, 23, nil); err != nil {
+		return cronSpec{}, err
+	}
+	if err = s.parseDayOfMonth(fields[3]); err != nil {
+		return cronSpec{}, err
+	}
+	if s.month, err = parseCronField(fields[4], 1, 12, cronMonthNames); err != nil {
+		return cronSpec{}, err
+	}
+	if err = s.parseDayOfWeek(fields[5]); err != nil {
+		return cronSpec{}, err
+	}
+	return s, nil
+}
+
+func (s *cronSpec) parseDayOfMonth(field string) error {
+	s.dom.star = strings.HasPrefix(field, "*") || field == "?"
+	var items []string
+	for _, item := range strings.Split(field, ",") {
+		switch {
+		case item == "L":
+			s.dom.values = append(s.dom.values, -1)
+		case strings.HasPrefix(item, "L-"):
+			n, err := strconv.Atoi(item[2:])
+			if err != nil || n < 0 || n > 30 {
+				return fmt.Errorf("%w: day-of-month %q", ErrInvalidCron, item)
+			}
+			s.dom.values = append(s.dom.values, -(n + 1))
+		case item == "LW":
+			s.domW = -1
+		case strings.HasSuffix(item, "W"):
+			n, err := strconv.Atoi(item[:len(item)-1])
+			if err != nil || n < 1 || n > 31 {
+				return fmt.Errorf("%w: day-of-month %q", ErrInvalidCron, item)
+			}
+			if n != 1 {
+				// The nearest weekday of a day depends on its own weekday.
+				s.unsupported = append(s.unsupported, item)
+			}
+			s.domW = 1
+		default:
+			items = append(items, item)
+		}
+	}
+	if s.domW != 0 && (len(items) != 0 || len(s.dom.values) != 0) {
+		s.unsupported = append(s.unsupported, "W in a list")
+	}
+	if len(items) != 0 {
+		f, err := parseCronField(strings.Join(items, ","), 1, 31, nil)
+		if err != nil {
+			return err
+		}
+		s.dom.values = append(s.dom.values, f.values...)
+		s.dom.all = f.all && len(s.dom.values) == len(f.values) && s.domW == 0
+	}
+	return nil
+}
+
+func (s *cronSpec) parseDayOfWeek(field string) error {
+	s.dow.star = strings.HasPrefix(field, "*") || field == "?"
+	var items []string
+	for _, item := range strings.Split(field, ",") {
+		var nth int
+		var day string
+		if i := strings.IndexByte(item, '#'); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n < 1 || n > 5 {
+				return fmt.Errorf("%w: day-of-week %q", ErrInvalidCron, item)
+			}
+			nth, day = n, item[:i]
+		} else if len(item) > 1 && strings.HasSuffix(item, "L") {
+			nth, day = -1, item[:len(item)-1]
+		} else {
+			items = append(items, item)
+			continue
+		}
+		wday, err := parseCronValue(day, 0, 7, cronWeekdayNames)
+		if err != nil {
+			return err
+		}
+		s.dowNth = append(s.dowNth, Weekday{weekday: cronToWeekday(wday), n: nth})
+	}
+	if len(items) != 0 {
+		f, err := parseCronField(strings.Join(items, ","), 0, 7, cronWeekdayNames)
+		if err != nil {
+			return err
+		}
+		seen := map[int]bool{}
+		for _, v := range f.values {
+			if wday := cronToWeekday(v); !seen[wday] {
+				seen[wday] = true
+				s.dow.values = append(s.dow.values, wday)
+			}
+		}
+		sort.Ints(s.dow.values)
+		s.dow.all = len(s.dow.values) == 7 && len(s.dowNth) == 0
+	}
+	return nil
+}
+
+// cronToWeekday converts a cron weekday, 0 or 7 for Sunday, to a Weekday
+// index, 0 for Monday.
+func cronToWeekday(wday int) int {
+	return (wday + 6) % 7
+}
+
+// parseCronField parses a list of values, names, ranges and steps within
+// [min, max].
+func parseCronField(field string, min, max int, names map[string]int) (cronField, error) {
+	f := cronField{star: strings.HasPrefix(field, "*") || field == "?"}
+	seen := map[int]bool{}
+	for _, item := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.IndexByte(item, '/'); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(item[i+1:]); err != nil || step < 1 {
+				return cronField{}, fmt.Errorf("%w: step %q", ErrInvalidCron, item)
+			}
+			item = item[:i]
+		}
+		lo, hi := min, max
+		switch {
+		case item == "*" || item == "?":
+		case strings.Contains(item, "-"):
+			i := strings.IndexByte(item, '-')
+			var err error
+			if lo, err = parseCronValue(item[:i], min, max, names); err != nil {
+				return cronField{}, err
+			}
+			if hi, err = parseCronValue(item[i+1:], min, max, names); err != nil {
+				return cronField{}, err
+			}
+			if lo > hi {
+				return cronField{}, fmt.Errorf("%w: range %q", ErrInvalidCron, item)
+			}
+		default:
+			var err error
+			if lo, err = parseCronValue(item, min, max, names); err != nil {
+				return cronField{}, err
+			}
+			if step == 1 {
+				hi = lo
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			if !seen[v] {
+				seen[v] = true
+				f.values = append(f.values, v)
+			}
+		}
+	}
+	sort.Ints(f.values)
+	f.all = len(f.values) == max-min+1
+	return f, nil
+}
+
+func parseCronValue(s string, min, max int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("%w: value %q", ErrInvalidCron, s)
+	}
+	return v, nil
+}
+
+// CronString returns the cron expression equivalent to the rule, with 5
+// fields or 6 when some occurrences are not on a whole minute. The values the
+// rule defaults are taken from Dtstart, whose location is ignored.
+// It returns ErrNotRepresentable listing the parts no cron expression can
+// represent, such as COUNT or an INTERVAL not dividing its unit.
+func (option *ROption) CronString() (string, error) {
+	var unsupported []string
+	check := func(cond bool, part string) {
+		if cond {
+			unsupported = append(unsupported, part)
+		}
+	}
+	check(option.Count != 0, "COUNT")
+	check(!option.Until.IsZero(), "UNTIL")
+	check(len(option.Byyearday) != 0, "BYYEARDAY")
+	check(len(option.Byweekno) != 0, "BYWEEKNO")
+	check(len(option.Byeaster) != 0, "BYEASTER")
+	check(len(option.Bybusinessday) != 0, "BYBUSINESSDAY")
+	check(option.Rscale != "", "RSCALE")
+	check(len(option.Byleapmonth) != 0, "leap BYMONTH")
+
+	freq, dtstart := option.Freq, option.Dtstart
+	interval := option.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	timeValues := func(unit Frequency, by []int, start, size int) []int {
+		switch {
+		case freq > unit || freq == unit && interval == 1:
+			if len(by) != 0 {
+				return by
+			}
+			return cronRange(0, size-1, 1)
+		case freq == unit:
+			check(size%interval != 0, "INTERVAL")
+			return cronIntersect(cronRange(start%interval, size-1, interval), by)
+		case len(by) != 0:
+			return by
+		default:
+			return []int{start}
+		}
+	}
+	seconds := timeValues(Secondly, option.Bysecond, dtstart.Second(), 60)
+	minutes := timeValues(Minutely, option.Byminute, dtstart.Minute(), 60)
+	hours := timeValues(Hourly, option.Byhour, dtstart.Hour(), 24)
+
+	mdays, wdays := option.Bymonthday, option.Byweekday
+	months := option.Bymonth
+	switch freq {
+	case Yearly:
+		check(interval != 1, "INTERVAL")
+		if len(months) == 0 && len(mdays) == 0 && len(wdays) == 0 {
+			months = []int{int(dtstart.Month())}
+		}
+	case Monthly:
+		check(12%interval != 0, "INTERVAL")
+		months = cronIntersect(cronRange(int(dtstart.Month()-1)%interval+1, 12, interval), months)
+	default:
+		check(freq <= Daily && interval != 1, "INTERVAL")
+	}
+	if len(months) == 0 {
+		months = cronRange(1, 12, 1)
+	}
+	if freq <= Monthly && len(mdays) == 0 && len(wdays) == 0 {
+		mdays = []int{dtstart.Day()}
+	}
+	if freq == Weekly {
+		check(len(mdays) != 0, "BYMONTHDAY")
+		if len(wdays) == 0 {
+			wdays = []Weekday{{weekday: toPyWeekday(dtstart.Weekday())}}
+		}
+	}
+
+	dom, dow := "*", "*"
+	if pos, ok := cronWeekdayPos(option); ok && len(seconds)*len(minutes)*len(hours) == 1 {
+		dom = map[int]string{1: "1W", -1: "LW"}[pos]
+	} else {
+		check(len(option.Bysetpos) != 0, "BYSETPOS")
+		check(len(mdays) != 0 && len(wdays) != 0, "BYMONTHDAY with BYDAY")
+		if len(mdays) != 0 {
+			dom = formatCronDays(mdays)
+		}
+		if len(wdays) != 0 {
+			var plain []int
+			var nth []string
+			for _, wday := range wdays {
+				day := (wday.weekday + 1) % 7
+				switch {
+				case wday.n == 0:
+					plain = append(plain, day)
+				// The ordinal is within the month of cron, a yearly rule without
+				// BYMONTH counts it within the year.
+				case freq == Monthly || freq == Yearly && len(option.Bymonth) != 0:
+					if wday.n == -1 {
+						nth = append(nth, fmt.Sprintf("%dL", day))
+					} else if wday.n > 0 && wday.n <= 5 {
+						nth = append(nth, fmt.Sprintf("%d#%d", day, wday.n))
+					} else {
+						check(true, fmt.Sprintf("BYDAY=%s", wday))
+					}
+				default:
+					check(true, fmt.Sprintf("BYDAY=%s", wday))
+				}
+			}
+			if len(plain) != 0 {
+				nth = append([]string{formatCronField(plain, 0, 6)}, nth...)
+			}
+			dow = strings.Join(nth, ",")
+		}
+	}
+	if len(unsupported) != 0 {
+		return "", fmt.Errorf("%w: %s", ErrNotRepresentable, strings.Join(unsupported, ", "))
+	}
+
+	fields := []string{
+		formatCronField(minutes, 0, 59),
+		formatCronField(hours, 0, 23),
+		dom,
+		formatCronField(months, 1, 12),
+		dow,
+	}
+	if len(seconds) != 1 || seconds[0] != 0 {
+		fields = append([]string{formatCronField(seconds, 0, 59)}, fields...)
+	}
+	return strings.Join(fields, " "), nil
+}
+
+// cronWeekdayPos returns the position of a monthly rule selecting the first
+// or last weekday of the month, which cron writes "1W" and "LW".
+func cronWeekdayPos(option *ROption) (int, bool) {
+	if option.Freq != Monthly || len(option.Bysetpos) != 1 || len(option.Bymonthday) != 0 ||
+		len(option.Byweekday) != 5 {
+		return 0, false
+	}
+	for i, wday := range option.Byweekday {
+		if wday.n != 0 || wday.weekday != i {
+			return 0, false
+		}
+	}
+	pos := option.Bysetpos[0]
+	return pos, pos == 1 || pos == -1
+}
+
+func cronRange(lo, hi, step int) []int {
+	var result []int
+	for v := lo; v <= hi; v += step {
+		result = append(result, v)
+	}
+	return result
+}
+
+// cronIntersect returns the values also in by, all of them when by is empty.
+func cronIntersect(values, by []int) []int {
+	if len(by) == 0 {
+		return values
+	}
+	var result []int
+	for _, v := range values {
+		if contains(by, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// formatCronField formats values within [min, max], "*" when all of them are
+// present, runs of consecutive values as ranges.
+func formatCronField(values []int, min, max int) string {
+	values = sortedInts(values)
+	var items []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] <= values[j]+1 {
+			j++
+		}
+		switch {
+		case values[i] == min && values[j] == max:
+			return "*"
+		case values[j]-values[i] >= 2:
+			items = append(items, fmt.Sprintf("%d-%d", values[i], values[j]))
+		default:
+			for k := i; k <= j; k++ {
+				if k == i || values[k] != values[k-1] {
+					items = append(items, strconv.Itoa(values[k]))
+				}
+			}
+		}
+		i = j + 1
+	}
+	return strings.Join(items, ",")
+}
+
+// formatCronDays formats days of month, "L" and "L-n" for the negative ones.
+func formatCronDays(days []int) string {
+	var positive []int
+	var items []string
+	for _, day := range days {
+		switch {
+		case day > 0:
+			positive = append(positive, day)
+		case day == -1:
+			items = append(items, "L")
+		default:
+			items = append(items, fmt.Sprintf("L-%d", -day-1))
+		}
+	}
+	if len(positive) != 0 {
+		items = append([]string{formatCronField(positive, 1, 31)}, items...)
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,158 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCronToROption(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	date := func(month time.Month, day, hour, min, sec int) time.Time {
+		return time.Date(2024, month, day, hour, min, sec, 0, time.UTC)
+	}
+	cases := []struct {
+		spec  string
+		rrule string
+		want  []time.Time
+	}{
+		{
+			spec:  "*/15 9-17 * * MON-FRI",
+			rrule: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9,10,11,12,13,14,15,16,17;BYMINUTE=0,15,30,45;BYSECOND=0",
+			want:  []time.Time{date(1, 1, 9, 0, 0), date(1, 1, 9, 15, 0), date(1, 1, 9, 30, 0)},
+		},
+		{
+			spec:  "30 * * * *",
+			rrule: "FREQ=HOURLY;BYMINUTE=30;BYSECOND=0",
+			want:  []time.Time{date(1, 1, 0, 30, 0), date(1, 1, 1, 30, 0), date(1, 1, 2, 30, 0)},
+		},
+		{
+			spec:  "*/20 * * * * *",
+			rrule: "FREQ=MINUTELY;BYSECOND=0,20,40",
+			want:  []time.Time{date(1, 1, 0, 0, 0), date(1, 1, 0, 0, 20), date(1, 1, 0, 0, 40)},
+		},
+		{
+			spec:  "0 12 L * *",
+			rrule: "FREQ=DAILY;BYMONTHDAY=-1;BYHOUR=12;BYMINUTE=0;BYSECOND=0",
+			want:  []time.Time{date(1, 31, 12, 0, 0), date(2, 29, 12, 0, 0), date(3, 31, 12, 0, 0)},
+		},
+		{
+			spec:  "0 8 LW * *",
+			rrule: "FREQ=MONTHLY;BYSETPOS=-1;BYDAY=MO,TU,WE,TH,FR;BYHOUR=8;BYMINUTE=0;BYSECOND=0",
+			want:  []time.Time{date(1, 31, 8, 0, 0), date(2, 29, 8, 0, 0), date(3, 29, 8, 0, 0)},
+		},
+		{
+			spec:  "0 8 1W * *",
+			rrule: "FREQ=MONTHLY;BYSETPOS=1;BYDAY=MO,TU,WE,TH,FR;BYHOUR=8;BYMINUTE=0;BYSECOND=0",
+			want:  []time.Time{date(1, 1, 8, 0, 0), date(2, 1, 8, 0, 0), date(3, 1, 8, 0, 0)},
+		},
+		{
+			spec:  "0 10 * JAN,JUN 5#2,1L",
+			rrule: "FREQ=MONTHLY;BYMONTH=1,6;BYDAY=+2FR,-1MO;BYHOUR=10;BYMINUTE=0;BYSECOND=0",
+			want:  []time.Time{date(1, 12, 10, 0, 0), date(1, 29, 10, 0, 0), date(6, 14, 10, 0, 0)},
+		},
+		{
+			spec:  "@yearly",
+			rrule: "FREQ=DAILY;BYMONTH=1;BYMONTHDAY=1;BYHOUR=0;BYMINUTE=0;BYSECOND=0",
+			want:  []time.Time{date(1, 1, 0, 0, 0), date(1, 1, 0, 0, 0).AddDate(1, 0, 0)},
+		},
+	}
+	for _, c := range cases {
+		option, err := CronToROption(c.spec, dtstart)
+		if !assert.NoError(t, err, c.spec) {
+			continue
+		}
+		assert.Equal(t, c.rrule, option.RRuleString(), c.spec)
+		r, err := NewRRule(option)
+		assert.NoError(t, err, c.spec)
+		for i, want := range c.want {
+			got, _ := r.Nth(i)
+			assert.Equal(t, want, got, c.spec)
+		}
+	}
+}
+
+func TestCronToIterable(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Cron matches either the day of month or the day of week.
+	_, err := CronToROption("0 0 13 * 5", dtstart)
+	assert.ErrorIs(t, err, ErrNotRepresentable)
+	source, err := CronToIterable("0 0 13 * 5", dtstart)
+	assert.NoError(t, err)
+	next := source.Iterator()
+	var got []time.Time
+	for i := 0; i < 4; i++ {
+		dt, _ := next()
+		got = append(got, dt)
+	}
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 13, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC),
+	}, got)
+}
+
+func TestCronErrors(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "* * * 13 *", "5-1 * * * *", "*/0 * * * *", "* * * * 8", "* * * * 5#6"} {
+		_, err := CronToROption(spec, dtstart)
+		assert.ErrorIs(t, err, ErrInvalidCron, spec)
+	}
+	_, err := CronToROption("0 0 15W * *", dtstart)
+	assert.ErrorIs(t, err, ErrNotRepresentable)
+	assert.Contains(t, err.Error(), "15W")
+	_, err = CronToROption("0 8,12 LW * *", dtstart)
+	assert.ErrorIs(t, err, ErrNotRepresentable)
+}
+
+func TestCronString(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC)
+	for _, spec := range []string{
+		"0,15,30,45 9-17 * * 1-5",
+		"30 * * * *",
+		"0,20,40 * * * * *",
+		"0 12 L,L-2 * *",
+		"0 8 LW * *",
+		"0 10 * 1,6 5#2,1L",
+		"0 0 1,15 * *",
+	} {
+		option, err := CronToROption(spec, dtstart)
+		assert.NoError(t, err, spec)
+		got, err := option.CronString()
+		assert.NoError(t, err, spec)
+		assert.Equal(t, spec, got)
+	}
+
+	cases := []struct {
+		option ROption
+		cron   string
+	}{
+		{ROption{Freq: Daily, Dtstart: dtstart}, "30 9 * * *"},
+		{ROption{Freq: Weekly, Dtstart: dtstart}, "30 9 * * 1"},
+		{ROption{Freq: Monthly, Interval: 3, Dtstart: dtstart.AddDate(0, 1, 0)}, "30 9 1 2,5,8,11 *"},
+		{ROption{Freq: Yearly, Dtstart: dtstart}, "30 9 1 1 *"},
+		{ROption{Freq: Minutely, Interval: 10, Byhour: []int{8}, Dtstart: dtstart}, "0,10,20,30,40,50 8 * * *"},
+		{ROption{Freq: Hourly, Interval: 6, Dtstart: dtstart}, "30 3,9,15,21 * * *"},
+	}
+	for _, c := range cases {
+		got, err := c.option.CronString()
+		assert.NoError(t, err, c.cron)
+		assert.Equal(t, c.cron, got)
+	}
+
+	option := ROption{Freq: Daily, Interval: 2, Count: 3, Byweekno: []int{1}, Dtstart: dtstart}
+	_, err := option.CronString()
+	assert.ErrorIs(t, err, ErrNotRepresentable)
+	assert.EqualError(t, err, "not representable: COUNT, BYWEEKNO, INTERVAL")
+	// The first Monday of the year, not of every month.
+	option = ROption{Freq: Yearly, Byweekday: []Weekday{Monday.Nth(1)}, Dtstart: dtstart}
+	_, err = option.CronString()
+	assert.ErrorIs(t, err, ErrNotRepresentable)
+	assert.EqualError(t, err, "not representable: BYDAY=+1MO")
+}
@@ -9,4 +9,13 @@ var (
 	ErrInvalidRRuleFormat = errors.New("invalid rrule format")
 	ErrInvalidateBound    = errors.New("invalid bound")
 	ErrBadFormat          = errors.New("bad format")
+	ErrInvalidSkip        = errors.New("invalid skip")
+	ErrUnsupportedRscale  = errors.New("unsupported rscale")
+	ErrInvalidAdjustment  = errors.New("invalid adjustment")
+	ErrInfinite           = errors.New("infinite recurrence")
+	ErrInvalidCron        = errors.New("invalid cron expression")
+	ErrNotRepresentable   = errors.New("not representable")
+	ErrInvalidMissedRun   = errors.New("invalid missed run policy")
+	ErrSchedulerStopped   = errors.New("scheduler stopped")
+	ErrSchemaMismatch     = errors.New("schema mismatch")
 )
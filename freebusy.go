@@ -0,0 +1,206 @@
+package rrule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Interval is the half-open time range [Start, End).
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the interval.
+func (i Interval) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+// WorkingHours restricts free slots to the same hours of the working days.
+type WorkingHours struct {
+	// Start and End are the wall-clock times of the working hours as
+	// offsets from midnight, e.g. 9 * time.Hour is 09:00 on the days of a
+	// DST transition too.
+	Start time.Duration
+	End   time.Duration
+	// Weekdays are the working days, Monday to Friday when empty.
+	Weekdays []Weekday
+	// Location is the time zone of the working hours, UTC when nil.
+	Location *time.Location
+}
+
+// intervals returns the working hours overlapping [start, end).
+func (w *WorkingHours) intervals(start, end time.Time) []Interval {
+	loc := w.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	weekdays := w.Weekdays
+	if len(weekdays) == 0 {
+		weekdays = []Weekday{Monday, Tuesday, Wednesday, Thursday, Friday}
+	}
+	var result []Interval
+	y, m, d := start.In(loc).Date()
+	for d := d - 1; ; d++ {
+		day := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		if !day.Before(end) {
+			break
+		}
+		if !weekdayContains(weekdays, day.Weekday()) {
+			continue
+		}
+		// The nanoseconds are normalized into the wall-clock fields.
+		i := Interval{
+			Start: time.Date(y, m, d, 0, 0, 0, int(w.Start), loc),
+			End:   time.Date(y, m, d, 0, 0, 0, int(w.End), loc),
+		}
+		if i.End.After(start) && i.Start.Before(end) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+func weekdayContains(list []Weekday, wday time.Weekday) bool {
+	for _, w := range list {
+		if w.weekday == toPyWeekday(wday) {
+			return true
+		}
+	}
+	return false
+}
+
+// FreeBusy holds the busy intervals of a collection of schedules within a
+// window, as the VFREEBUSY component of RFC 5545 section 3.6.4.
+type FreeBusy struct {
+	// UID identifies the VFREEBUSY component, it is derived from the DTSTAMP
+	// and the window when empty.
+	UID string
+	// Stamp is the DTSTAMP of the component, the current time of Clock when
+	// zero.
+	Stamp time.Time
+	// Clock tells the current time, the SystemClock when nil.
+	Clock Clock
+	Start time.Time
+	End   time.Time
+	// Busy lists the merged busy intervals in order.
+	Busy []Interval
+}
+
+// NewFreeBusy computes the busy intervals of schedules within [start, end),
+// merging the overlapping and adjacent ones.
+func NewFreeBusy(schedules []Schedule, start, end time.Time) *FreeBusy {
+	var busy []Interval
+	for _, schedule := range schedules {
+		for _, dt := range schedule.occurrencesIn(start, end) {
+			i := Interval{Start: dt, End: dt.Add(schedule.Duration)}
+			if i.Start.Before(start) {
+				i.Start = start
+			}
+			if i.End.After(end) {
+				i.End = end
+			}
+			busy = append(busy, i)
+		}
+	}
+	sort.Slice(busy, func(i, j int) bool { return busy[i].Start.Before(busy[j].Start) })
+
+	fb := &FreeBusy{Start: start, End: end}
+	for _, i := range busy {
+		if n := len(fb.Busy); n != 0 && !i.Start.After(fb.Busy[n-1].End) {
+			if i.End.After(fb.Busy[n-1].End) {
+				fb.Busy[n-1].End = i.End
+			}
+			continue
+		}
+		fb.Busy = append(fb.Busy, i)
+	}
+	return fb
+}
+
+// Free returns the free slots of at least min within the window, limited to
+// the working hours when they are not nil.
+func (fb *FreeBusy) Free(min time.Duration, hours *WorkingHours) []Interval {
+	var free []Interval
+	cursor := fb.Start
+	for _, i := range fb.Busy {
+		if i.Start.After(cursor) {
+			free = append(free, Interval{Start: cursor, End: i.Start})
+		}
+		cursor = i.End
+	}
+	if fb.End.After(cursor) {
+		free = append(free, Interval{Start: cursor, End: fb.End})
+	}
+	if hours != nil {
+		free = intersectIntervals(free, hours.intervals(fb.Start, fb.End))
+	}
+
+	result := free[:0]
+	for _, i := range free {
+		if i.Duration() >= min {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// intersectIntervals returns the intersection of two ordered lists of
+// disjoint intervals.
+func intersectIntervals(a, b []Interval) []Interval {
+	var result []Interval
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		start, end := a[i].Start, a[i].End
+		if b[j].Start.After(start) {
+			start = b[j].Start
+		}
+		if b[j].End.Before(end) {
+			end = b[j].End
+		}
+		if start.Before(end) {
+			result = append(result, Interval{Start: start, End: end})
+		}
+		if a[i].End.Before(b[j].End) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return result
+}
+
+// String returns the VFREEBUSY component, e.g.
+//
+//	BEGIN:VFREEBUSY
+//	UID:19970901T115957Z-76A912@example.com
+//	DTSTAMP:19970901T120000Z
+//	DTSTART:19980313T141711Z
+//	DTEND:19980410T141711Z
+//	FREEBUSY:19980314T233000Z/19980315T003000Z
+//	END:VFREEBUSY
+func (fb *FreeBusy) String() string {
+	stamp := fb.Stamp
+	if stamp.IsZero() {
+		stamp = clockOrSystem(fb.Clock).Now()
+	}
+	// UID is required, RFC 5545 section 3.6.4.
+	uid := fb.UID
+	if uid == "" {
+		uid = fmt.Sprintf("%s-%s-%s@rrule-go", timeToStr(stamp), timeToStr(fb.Start), timeToStr(fb.End))
+	}
+	lines := []string{
+		"BEGIN:VFREEBUSY",
+		fmt.Sprintf("UID:%s", uid),
+		fmt.Sprintf("DTSTAMP:%s", timeToStr(stamp)),
+		fmt.Sprintf("DTSTART:%s", timeToStr(fb.Start)),
+		fmt.Sprintf("DTEND:%s", timeToStr(fb.End)),
+	}
+	for _, i := range fb.Busy {
+		lines = append(lines, fmt.Sprintf("FREEBUSY:%s/%s", timeToStr(i.Start), timeToStr(i.End)))
+	}
+	lines = append(lines, "END:VFREEBUSY")
+	// Each content line ends with CRLF, RFC 5545 section 3.1.
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
@@ -0,0 +1,100 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreeBusy(t *testing.T) {
+	t.Parallel()
+	at := func(d, h, m int) time.Time { return time.Date(2024, 6, d, h, m, 0, 0, time.UTC) }
+	// Daily stand-up 9:30-10:00 and a weekly review Monday 9:45-11:00.
+	standup, _ := NewRRule(ROption{Freq: Daily, Dtstart: at(1, 9, 30)})
+	review, _ := NewRRule(ROption{Freq: Weekly, Dtstart: at(3, 9, 45)})
+	lunch := &Set{}
+	lunch.RDate(at(3, 12, 0))
+	schedules := []Schedule{
+		{Source: standup, Duration: 30 * time.Minute},
+		{Source: review, Duration: 75 * time.Minute},
+		{Source: lunch, Duration: time.Hour},
+	}
+
+	fb := NewFreeBusy(schedules, at(3, 0, 0), at(5, 0, 0))
+	assert.Equal(t, []Interval{
+		{Start: at(3, 9, 30), End: at(3, 11, 0)},
+		{Start: at(3, 12, 0), End: at(3, 13, 0)},
+		{Start: at(4, 9, 30), End: at(4, 10, 0)},
+	}, fb.Busy)
+
+	hours := &WorkingHours{Start: 9 * time.Hour, End: 17 * time.Hour}
+	assert.Equal(t, []Interval{
+		{Start: at(3, 13, 0), End: at(3, 17, 0)},
+		{Start: at(4, 10, 0), End: at(4, 17, 0)},
+	}, fb.Free(2*time.Hour, hours))
+	assert.Equal(t, []Interval{
+		{Start: at(3, 0, 0), End: at(3, 9, 30)},
+		{Start: at(3, 13, 0), End: at(4, 9, 30)},
+		{Start: at(4, 10, 0), End: at(5, 0, 0)},
+	}, fb.Free(2*time.Hour, nil))
+	// June 8 and 9 are the weekend.
+	assert.Empty(t, NewFreeBusy(schedules, at(8, 0, 0), at(10, 0, 0)).Free(time.Minute, hours))
+
+	fb.UID = "1@example.com"
+	fb.Stamp = at(1, 0, 0)
+	assert.Equal(t, "BEGIN:VFREEBUSY\r\n"+
+		"UID:1@example.com\r\n"+
+		"DTSTAMP:20240601T000000Z\r\n"+
+		"DTSTART:20240603T000000Z\r\n"+
+		"DTEND:20240605T000000Z\r\n"+
+		"FREEBUSY:20240603T093000Z/20240603T110000Z\r\n"+
+		"FREEBUSY:20240603T120000Z/20240603T130000Z\r\n"+
+		"FREEBUSY:20240604T093000Z/20240604T100000Z\r\n"+
+		"END:VFREEBUSY\r\n", fb.String())
+}
+
+// stoppedClock is a Clock whose time does not change.
+type stoppedClock struct {
+	now time.Time
+}
+
+func (c stoppedClock) Now() time.Time {
+	return c.now
+}
+
+func (c stoppedClock) NewTimer(d time.Duration) Timer {
+	return SystemClock.NewTimer(d)
+}
+
+func TestFreeBusyStamp(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	fb := NewFreeBusy(nil, now, now.AddDate(0, 0, 1))
+	fb.Clock = stoppedClock{now}
+	assert.Equal(t, "BEGIN:VFREEBUSY\r\n"+
+		"UID:20240601T090000Z-20240601T090000Z-20240602T090000Z@rrule-go\r\n"+
+		"DTSTAMP:20240601T090000Z\r\n"+
+		"DTSTART:20240601T090000Z\r\n"+
+		"DTEND:20240602T090000Z\r\n"+
+		"END:VFREEBUSY\r\n", fb.String())
+}
+
+func TestWorkingHoursDST(t *testing.T) {
+	t.Parallel()
+	paris, _ := time.LoadLocation("Europe/Paris")
+	hours := &WorkingHours{Start: 9 * time.Hour, End: 17 * time.Hour, Location: paris,
+		Weekdays: []Weekday{Sunday}}
+	// Summer and winter time start on the last Sunday of March and October.
+	for _, start := range []time.Time{
+		time.Date(2024, 3, 31, 0, 0, 0, 0, paris),
+		time.Date(2024, 10, 27, 0, 0, 0, 0, paris),
+	} {
+		fb := NewFreeBusy(nil, start, start.AddDate(0, 0, 1))
+		y, m, d := start.Date()
+		assert.Equal(t, []Interval{{
+			Start: time.Date(y, m, d, 9, 0, 0, 0, paris),
+			End:   time.Date(y, m, d, 17, 0, 0, 0, paris),
+		}}, fb.Free(time.Hour, hours))
+	}
+}
@@ -0,0 +1,96 @@
+package rrule
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// postgresDialect is the name of the GORM dialector of PostgreSQL, on which
+// RRule and Set are stored as the composites of Migrations. Other databases
+// store their RFC 5545 text, which Scan reads as well.
+const postgresDialect = "postgres"
+
+// GormDataType returns the GORM data type of RRule.
+func (RRule) GormDataType() string {
+	return "rrule"
+}
+
+// GormDBDataType returns the column type of RRule for the dialect of db.
+func (RRule) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if db.Dialector.Name() == postgresDialect {
+		return "_rrule.RRULE"
+	}
+	return "TEXT"
+}
+
+// GormValue returns the value of RRule for the dialect of db.
+func (t RRule) GormValue(ctx context.Context, db *gorm.DB) clause.Expr {
+	if db.Dialector.Name() == postgresDialect {
+		v, err := t.Value()
+		if err != nil {
+			_ = db.AddError(err)
+		}
+		return clause.Expr{SQL: "?", Vars: []interface{}{v}}
+	}
+	return clause.Expr{SQL: "?", Vars: []interface{}{t.text()}}
+}
+
+// GormDataType returns the GORM data type of Set.
+func (Set) GormDataType() string {
+	return "rruleset"
+}
+
+// GormDBDataType returns the column type of Set for the dialect of db.
+func (Set) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if db.Dialector.Name() == postgresDialect {
+		return "_rrule.RRULESET"
+	}
+	return "TEXT"
+}
+
+// GormValue returns the value of Set for the dialect of db.
+func (t Set) GormValue(ctx context.Context, db *gorm.DB) clause.Expr {
+	if db.Dialector.Name() == postgresDialect {
+		v, err := t.Value()
+		if err != nil {
+			_ = db.AddError(err)
+		}
+		return clause.Expr{SQL: "?", Vars: []interface{}{v}}
+	}
+	return clause.Expr{SQL: "?", Vars: []interface{}{t.String()}}
+}
+
+// GormDataType returns the GORM data type of TextRRule.
+func (TextRRule) GormDataType() string {
+	return "string"
+}
+
+// GormDBDataType returns TEXT, whatever the dialect of db.
+func (TextRRule) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return "TEXT"
+}
+
+// GormValue returns the Value of TextRRule, whatever the dialect of db.
+func (t TextRRule) GormValue(ctx context.Context, db *gorm.DB) clause.Expr {
+	v, _ := t.Value()
+	return clause.Expr{SQL: "?", Vars: []interface{}{v}}
+}
+
+// GormDataType returns the GORM data type of TextSet.
+func (TextSet) GormDataType() string {
+	return "string"
+}
+
+// GormDBDataType returns TEXT, whatever the dialect of db.
+func (TextSet) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return "TEXT"
+}
+
+// GormValue returns the Value of TextSet, whatever the dialect of db.
+func (t TextSet) GormValue(ctx context.Context, db *gorm.DB) clause.Expr {
+	v, _ := t.Value()
+	return clause.Expr{SQL: "?", Vars: []interface{}{v}}
+}
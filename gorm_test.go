@@ -0,0 +1,93 @@
+package rrule
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// fakeDialector is a gorm.Dialector only telling its name.
+type fakeDialector struct {
+	gorm.Dialector
+	name string
+}
+
+func (d fakeDialector) Name() string {
+	return d.name
+}
+
+func TestGormDataType(t *testing.T) {
+	t.Parallel()
+	type model struct {
+		Rule RRule
+		Set  Set
+	}
+	s, err := schema.Parse(&model{}, &sync.Map{}, schema.NamingStrategy{})
+	assert.NoError(t, err)
+	assert.Equal(t, schema.DataType("rrule"), s.LookUpField("Rule").DataType)
+	assert.Equal(t, schema.DataType("rruleset"), s.LookUpField("Set").DataType)
+
+	postgres := &gorm.DB{Config: &gorm.Config{Dialector: fakeDialector{name: "postgres"}}}
+	sqlite := &gorm.DB{Config: &gorm.Config{Dialector: fakeDialector{name: "sqlite"}}}
+	assert.Equal(t, "_rrule.RRULE", RRule{}.GormDBDataType(postgres, s.LookUpField("Rule")))
+	assert.Equal(t, "_rrule.RRULESET", Set{}.GormDBDataType(postgres, s.LookUpField("Set")))
+	assert.Equal(t, "TEXT", RRule{}.GormDBDataType(sqlite, s.LookUpField("Rule")))
+	assert.Equal(t, "TEXT", Set{}.GormDBDataType(sqlite, s.LookUpField("Set")))
+}
+
+func TestGormValue(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	postgres := &gorm.DB{Config: &gorm.Config{Dialector: fakeDialector{name: "postgres"}}}
+	sqlite := &gorm.DB{Config: &gorm.Config{Dialector: fakeDialector{name: "sqlite"}}}
+	dtstart := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	r, _ := NewRRule(ROption{Freq: Daily, Count: 3, Dtstart: dtstart})
+	set := Set{}
+	set.RRule(r)
+	set.ExDate(dtstart.AddDate(0, 0, 1))
+
+	assert.Equal(t, clause.Expr{SQL: "?", Vars: []interface{}{"(DAILY,1,3,,,,,,,,,,,MO,)"}}, r.GormValue(ctx, postgres))
+	assert.Equal(t, clause.Expr{SQL: "?", Vars: []interface{}{
+		`("2024-01-01 09:00:00",,"(DAILY,1,3,,,,,,,,,,,MO,)",,,"{""2024-01-02 09:00:00""}")`,
+	}}, set.GormValue(ctx, postgres))
+
+	// The RFC 5545 text keeps the defaulted DTSTART.
+	now, _ := NewRRule(ROption{Freq: Daily, Count: 3})
+	expr := now.GormValue(ctx, sqlite)
+	rule, err := StrToRRule(expr.Vars[0].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, now.All(), rule.All())
+
+	expr = set.GormValue(ctx, sqlite)
+	assert.Equal(t, set.String(), expr.Vars[0])
+	scanned, err := StrToRRuleSet(expr.Vars[0].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{dtstart, dtstart.AddDate(0, 0, 2)}, scanned.All())
+}
+
+func TestTextGormValue(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	postgres := &gorm.DB{Config: &gorm.Config{Dialector: fakeDialector{name: "postgres"}}}
+	dtstart := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	r, _ := NewRRule(ROption{Freq: Daily, Count: 3, Dtstart: dtstart})
+	set := Set{}
+	set.RRule(r)
+	set.ExDate(dtstart.AddDate(0, 0, 1))
+
+	// The TEXT types ignore the dialect.
+	assert.Equal(t, "TEXT", TextRRule{}.GormDBDataType(postgres, nil))
+	assert.Equal(t, "TEXT", TextSet{}.GormDBDataType(postgres, nil))
+	assert.Equal(t, r.String(), TextRRule{*r}.GormValue(ctx, postgres).Vars[0])
+	assert.Equal(t, set.String(), TextSet{set}.GormValue(ctx, postgres).Vars[0])
+
+	// The zero values are NULL.
+	assert.Equal(t, clause.Expr{SQL: "?", Vars: []interface{}{nil}}, TextRRule{}.GormValue(ctx, postgres))
+	assert.Equal(t, clause.Expr{SQL: "?", Vars: []interface{}{nil}}, TextSet{}.GormValue(ctx, postgres))
+}
@@ -0,0 +1,82 @@
+// Package composite reads the text representation of the PostgreSQL
+// composite values of the _rrule schema, for the rrule package and its rrule
+// subpackage.
+package composite
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrMalformed is returned for a text that is not a composite value.
+var ErrMalformed = errors.New("malformed composite value")
+
+// Split splits the text representation of a composite value into its
+// fields, removing the quotes around them.
+func Split(s string) ([]string, error) {
+	if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+		return nil, fmt.Errorf("%w: %s", ErrMalformed, s)
+	}
+	var (
+		fields []string
+		field  strings.Builder
+		quoted bool
+	)
+	for pos := 1; pos < len(s)-1; pos++ {
+		switch c := s[pos]; {
+		case quoted && c == '\\' && pos+1 < len(s)-1:
+			pos++
+			field.WriteByte(s[pos])
+		case quoted && c == '"' && s[pos+1] == '"':
+			pos++
+			field.WriteByte(c)
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteByte(c)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("%w: unterminated quote %s", ErrMalformed, s)
+	}
+
+	return append(fields, field.String()), nil
+}
+
+// ParseInt parses an integer field, a NULL one being 0.
+func ParseInt(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrMalformed, s)
+	}
+
+	return v, nil
+}
+
+// ParseIntArray parses an integer array field, a NULL or empty one being
+// nil.
+func ParseIntArray(s string) ([]int, error) {
+	s = strings.Trim(s, "{}")
+	if s == "" {
+		return nil, nil
+	}
+	fields := strings.Split(s, ",")
+	result := make([]int, len(fields))
+	for i, field := range fields {
+		v, err := ParseInt(field)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = v
+	}
+
+	return result, nil
+}
@@ -0,0 +1,37 @@
+package composite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+	fields, err := Split(`(DAILY,,"{1,2}","a ""quoted"" \\ field")`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DAILY", "", "{1,2}", `a "quoted" \ field`}, fields)
+
+	for _, s := range []string{"", "DAILY", `(DAILY,"1)`} {
+		_, err = Split(s)
+		assert.ErrorIs(t, err, ErrMalformed, s)
+	}
+}
+
+func TestParseIntArray(t *testing.T) {
+	t.Parallel()
+	v, err := ParseIntArray("{1,-2,3}")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, -2, 3}, v)
+	for _, s := range []string{"", "{}"} {
+		v, err = ParseIntArray(s)
+		assert.NoError(t, err)
+		assert.Nil(t, v)
+	}
+	_, err = ParseIntArray("{1,x}")
+	assert.ErrorIs(t, err, ErrMalformed)
+
+	i, err := ParseInt("")
+	assert.NoError(t, err)
+	assert.Zero(t, i)
+}
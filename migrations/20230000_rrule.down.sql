@@ -0,0 +1,3 @@
+-- Drops the types, functions, operators and casts of 20230000_rrule, which
+-- all depend on the _rrule schema.
+DROP SCHEMA IF EXISTS _rrule CASCADE;
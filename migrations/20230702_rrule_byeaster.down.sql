@@ -0,0 +1,150 @@
+-- Restore the functions of 20230000_rrule before dropping the column they read.
+CREATE OR REPLACE FUNCTION _rrule.rrule (TEXT)
+RETURNS _rrule.RRULE AS $$
+DECLARE
+  result _rrule.RRULE;
+BEGIN
+  WITH "tokens" AS (
+    WITH A20 as (SELECT _rrule.parse_line($1::text, 'RRULE') "r"),
+    -- Split each key value pair into an array, e.g. {'FREQ', 'DAILY'}
+    A30 as (SELECT regexp_split_to_array("r", '=') AS "y" FROM A20)
+    SELECT "y"[1] AS "key", "y"[2] AS "val" FROM A30
+  ),
+  candidate AS (
+    SELECT
+      (SELECT "val"::_rrule.FREQ FROM "tokens" WHERE "key" = 'FREQ') AS "freq",
+      (SELECT "val"::INTEGER FROM "tokens" WHERE "key" = 'INTERVAL') AS "interval",
+      (SELECT "val"::INTEGER FROM "tokens" WHERE "key" = 'COUNT') AS "count",
+      (SELECT "val"::TIMESTAMP FROM "tokens" WHERE "key" = 'UNTIL') AS "until",
+      (SELECT _rrule.integer_array("val") FROM "tokens" WHERE "key" = 'BYSECOND') AS "bysecond",
+      (SELECT _rrule.integer_array("val") FROM "tokens" WHERE "key" = 'BYMINUTE') AS "byminute",
+      (SELECT _rrule.integer_array("val") FROM "tokens" WHERE "key" = 'BYHOUR') AS "byhour",
+      (SELECT _rrule.day_array("val") FROM "tokens" WHERE "key" = 'BYDAY') AS "byday",
+      (SELECT _rrule.integer_array("val") FROM "tokens" WHERE "key" = 'BYMONTHDAY') AS "bymonthday",
+      (SELECT _rrule.integer_array("val") FROM "tokens" WHERE "key" = 'BYYEARDAY') AS "byyearday",
+      (SELECT _rrule.integer_array("val") FROM "tokens" WHERE "key" = 'BYWEEKNO') AS "byweekno",
+      (SELECT _rrule.integer_array("val") FROM "tokens" WHERE "key" = 'BYMONTH') AS "bymonth",
+      (SELECT _rrule.integer_array("val") FROM "tokens" WHERE "key" = 'BYSETPOS') AS "bysetpos",
+      (SELECT "val"::_rrule.DAY FROM "tokens" WHERE "key" = 'WKST') AS "wkst"
+  )
+  SELECT
+    "freq",
+    -- Default value for INTERVAL
+    COALESCE("interval", 1) AS "interval",
+    "count",
+    "until",
+    "bysecond",
+    "byminute",
+    "byhour",
+    "byday",
+    "bymonthday",
+    "byyearday",
+    "byweekno",
+    "bymonth",
+    "bysetpos",
+    -- DEFAULT value for wkst
+    COALESCE("wkst", 'MO') AS "wkst"
+  INTO result
+  FROM candidate;
+
+  PERFORM _rrule.validate_rrule(result);
+
+  RETURN result;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE STRICT;
+
+
+CREATE OR REPLACE FUNCTION _rrule.text(_rrule.RRULE)
+RETURNS TEXT AS $$
+  SELECT regexp_replace(
+    'RRULE:'
+    || COALESCE('FREQ=' || $1."freq" || ';', '')
+    || CASE WHEN $1."interval" = 1 THEN '' ELSE COALESCE('INTERVAL=' || $1."interval" || ';', '') END
+    || COALESCE('COUNT=' || $1."count" || ';', '')
+    || COALESCE('UNTIL=' || $1."until" || ';', '')
+    || COALESCE('BYSECOND=' || _rrule.array_join($1."bysecond", ',') || ';', '')
+    || COALESCE('BYMINUTE=' || _rrule.array_join($1."byminute", ',') || ';', '')
+    || COALESCE('BYHOUR=' || _rrule.array_join($1."byhour", ',') || ';', '')
+    || COALESCE('BYDAY=' || _rrule.array_join($1."byday", ',') || ';', '')
+    || COALESCE('BYMONTHDAY=' || _rrule.array_join($1."bymonthday", ',') || ';', '')
+    || COALESCE('BYYEARDAY=' || _rrule.array_join($1."byyearday", ',') || ';', '')
+    || COALESCE('BYWEEKNO=' || _rrule.array_join($1."byweekno", ',') || ';', '')
+    || COALESCE('BYMONTH=' || _rrule.array_join($1."bymonth", ',') || ';', '')
+    || COALESCE('BYSETPOS=' || _rrule.array_join($1."bysetpos", ',') || ';', '')
+    || CASE WHEN $1."wkst" = 'MO' THEN '' ELSE COALESCE('WKST=' || $1."wkst" || ';', '') END
+  , ';$', '');
+$$ LANGUAGE SQL IMMUTABLE STRICT;
+
+CREATE OR REPLACE FUNCTION _rrule.jsonb_to_rrule("input" jsonb)
+RETURNS _rrule.RRULE AS $$
+DECLARE
+  result _rrule.RRULE;
+BEGIN
+  IF (SELECT count(*) = 0 FROM jsonb_object_keys("input") WHERE "input"::TEXT <> 'null') THEN
+    RETURN NULL;
+  END IF;
+
+  SELECT
+    "freq",
+    -- Default value for INTERVAL
+    COALESCE("interval", 1) AS "interval",
+    "count",
+    "until",
+    "bysecond",
+    "byminute",
+    "byhour",
+    "byday",
+    "bymonthday",
+    "byyearday",
+    "byweekno",
+    "bymonth",
+    "bysetpos",
+    -- DEFAULT value for wkst
+    COALESCE("wkst", 'MO') AS "wkst"
+  INTO result
+  FROM jsonb_to_record("input") as x(
+    "freq" _rrule.FREQ,
+    "interval" integer,
+    "count" INTEGER,
+    "until" text,
+    "bysecond" integer[],
+    "byminute" integer[],
+    "byhour" integer[],
+    "byday" text[],
+    "bymonthday" integer[],
+    "byyearday" integer[],
+    "byweekno" integer[],
+    "bymonth" integer[],
+    "bysetpos" integer[],
+    "wkst" _rrule.DAY
+  );
+
+  PERFORM _rrule.validate_rrule(result);
+
+  RETURN result;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE STRICT;
+
+CREATE OR REPLACE FUNCTION _rrule.rrule_to_jsonb("input" _rrule.RRULE)
+RETURNS jsonb AS $$
+BEGIN
+  RETURN jsonb_strip_nulls(jsonb_build_object(
+    'freq', "input"."freq",
+    'interval', "input"."interval",
+    'count', "input"."count",
+    'until', "input"."until",
+    'bysecond', "input"."bysecond",
+    'byminute', "input"."byminute",
+    'byhour', "input"."byhour",
+    'byday', "input"."byday",
+    'bymonthday', "input"."bymonthday",
+    'byyearday', "input"."byyearday",
+    'byweekno', "input"."byweekno",
+    'bymonth', "input"."bymonth",
+    'bysetpos', "input"."bysetpos",
+    'wkst', "input"."wkst"
+  ));
+END;
+$$ LANGUAGE plpgsql IMMUTABLE STRICT;
+
+ALTER TABLE _rrule.RRULE DROP COLUMN IF EXISTS "byeaster";
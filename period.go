@@ -0,0 +1,223 @@
+package rrule
+
+import "time"
+
+// periodIndex returns the index, in intervals from the period of DTSTART, of
+// the last period starting at or before t, aligned is false when INTERVAL
+// skips the period of t. t must not be before DTSTART.
+func (r *RRule) periodIndex(t time.Time) (k int, aligned bool) {
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+	dyear, dmonth, dday := r.dtstart.Date()
+	dhour, dminute, dsecond := r.dtstart.Clock()
+	days := fixedFromDate(year, month, day) - fixedFromDate(dyear, dmonth, dday)
+
+	var elapsed int
+	switch r.freq {
+	case Yearly:
+		elapsed = year - dyear
+	case Monthly:
+		elapsed = (year-dyear)*12 + int(month-dmonth)
+	case Weekly:
+		// Weeks start on WKST, except the first one starting on DTSTART.
+		days += pymod(toPyWeekday(r.dtstart.Weekday())-r.wkst, 7) - pymod(toPyWeekday(t.Weekday())-r.wkst, 7)
+		elapsed = days / 7
+	case Daily:
+		elapsed = days
+	case Hourly:
+		elapsed = days*24 + hour - dhour
+	case Minutely:
+		elapsed = (days*24+hour-dhour)*60 + minute - dminute
+	case Secondly:
+		elapsed = ((days*24+hour-dhour)*60+minute-dminute)*60 + second - dsecond
+	}
+	return elapsed / r.interval, elapsed%r.interval == 0
+}
+
+// nthPeriod returns an iterator starting with the kth period after the one
+// of DTSTART and the start of that period.
+func (r *RRule) nthPeriod(k int) (Next, time.Time) {
+	if k == 0 {
+		return r.Iterator(), r.dtstart
+	}
+	year, month, day := r.dtstart.Date()
+	hour, minute, second := r.dtstart.Clock()
+	loc := r.dtstart.Location()
+	n := k * r.interval
+	switch r.freq {
+	case Yearly:
+		return r.iteratorAt(year+n, month, 1, hour, minute, second, 0), time.Date(year+n, 1, 1, 0, 0, 0, 0, loc)
+	case Monthly:
+		year, month, _ = time.Date(year, month+time.Month(n), 1, 0, 0, 0, 0, time.UTC).Date()
+		return r.iteratorAt(year, month, 1, hour, minute, second, 0), time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	case Weekly:
+		day += 7*n - pymod(toPyWeekday(r.dtstart.Weekday())-r.wkst, 7)
+		year, month, day = time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Date()
+		return r.iteratorAt(year, month, day, hour, minute, second, r.wkst), time.Date(year, month, day, 0, 0, 0, 0, loc)
+	case Daily:
+		day += n
+	case Hourly:
+		hour += n
+	case Minutely:
+		minute += n
+	default:
+		second += n
+	}
+	civil := time.Date(year, month, day, hour, minute, second, 0, time.UTC)
+	year, month, day = civil.Date()
+	hour, minute, second = civil.Clock()
+	var start time.Time
+	switch r.freq {
+	case Daily:
+		start = time.Date(year, month, day, 0, 0, 0, 0, loc)
+	case Hourly:
+		start = time.Date(year, month, day, hour, 0, 0, 0, loc)
+	case Minutely:
+		start = time.Date(year, month, day, hour, minute, 0, 0, loc)
+	default:
+		start = time.Date(year, month, day, hour, minute, second, 0, loc)
+	}
+	return r.iteratorAt(year, month, day, hour, minute, second, toPyWeekday(civil.Weekday())), start
+}
+
+// periodIterator returns an iterator starting with the period of t, ok is
+// false when the period is skipped by INTERVAL.
+func (r *RRule) periodIterator(t time.Time) (next Next, ok bool) {
+	k, aligned := r.periodIndex(t)
+	if !aligned {
+		return nil, false
+	}
+	next, _ = r.nthPeriod(k)
+	return next, true
+}
+
+// periodSize returns the number of occurrences of every period but the
+// first one, 0 when it varies from a period to another.
+func (r *RRule) periodSize() int {
+	if r.skip != Omit || r.calendar != nil ||
+		len(r.bysetpos) != 0 || len(r.byweekno) != 0 || len(r.byyearday) != 0 ||
+		len(r.byeaster) != 0 || len(r.bynmonthday) != 0 || len(r.bydays) != 0 ||
+		len(r.bybusinessday) != 0 {
+		return 0
+	}
+	for _, mday := range r.bymonthday {
+		if mday > 28 {
+			return 0
+		}
+	}
+	days := len(distinctInts(r.bymonthday))
+	switch r.freq {
+	case Yearly:
+		if len(r.byweekday) != 0 {
+			return 0
+		}
+		return len(distinctInts(r.bymonth)) * days * len(r.timeset)
+	case Monthly:
+		if len(r.bymonth) != 0 || len(r.byweekday) != 0 {
+			return 0
+		}
+		return days * len(r.timeset)
+	case Weekly:
+		if len(r.bymonth) != 0 || len(r.bymonthday) != 0 {
+			return 0
+		}
+		return len(distinctInts(r.byweekday)) * len(r.timeset)
+	}
+	if len(r.bymonth) != 0 || len(r.bymonthday) != 0 || len(r.byweekday) != 0 {
+		return 0
+	}
+	switch r.freq {
+	case Daily:
+		return len(r.timeset)
+	case Hourly:
+		if len(r.byhour) != 0 {
+			return 0
+		}
+		return len(r.byminute) * len(r.bysecond)
+	case Minutely:
+		if len(r.byhour) != 0 || len(r.byminute) != 0 {
+			return 0
+		}
+		return len(r.bysecond)
+	default:
+		if len(r.byhour) != 0 || len(r.byminute) != 0 || len(r.bysecond) != 0 {
+			return 0
+		}
+		return 1
+	}
+}
+
+// countBefore returns the number of occurrences before dt, skipping whole
+// periods when their size is constant.
+func (r *RRule) countBefore(dt time.Time) int {
+	if !dt.Before(r.until) {
+		dt = r.until.Add(time.Second)
+	}
+	if !dt.After(r.dtstart) {
+		return 0
+	}
+	n := 0
+	next := r.Iterator()
+	if size := r.periodSize(); size != 0 {
+		if k, _ := r.periodIndex(dt.In(r.dtstart.Location())); k > 0 {
+			_, start := r.nthPeriod(1)
+			first := r.Iterator()
+			for v, ok := first(); ok && v.Before(start); v, ok = first() {
+				n++
+			}
+			next, _ = r.nthPeriod(k)
+			n += (k - 1) * size
+		}
+	}
+	for v, ok := next(); ok && v.Before(dt); v, ok = next() {
+		n++
+	}
+	if r.count != 0 && n > r.count {
+		return r.count
+	}
+	return n
+}
+
+// nth returns the nth occurrence, skipping whole periods when their size is
+// constant.
+func (r *RRule) nth(n int) (time.Time, bool) {
+	if n < 0 || r.count != 0 && n >= r.count {
+		return time.Time{}, false
+	}
+	next := r.Iterator()
+	if size := r.periodSize(); size != 0 {
+		_, start := r.nthPeriod(1)
+		first := 0
+		for v, ok := next(); ok && v.Before(start); v, ok = next() {
+			if first == n {
+				return v, true
+			}
+			first++
+		}
+		k := (n-first)/size + 1
+		if k > 1 {
+			if _, start = r.nthPeriod(k); start.After(r.until) || start.Year() > MAXYEAR {
+				return time.Time{}, false
+			}
+		}
+		next, _ = r.nthPeriod(k)
+		n = (n - first) % size
+	}
+	for v, ok := next(); ok; v, ok = next() {
+		if n == 0 {
+			return v, true
+		}
+		n--
+	}
+	return time.Time{}, false
+}
+
+func distinctInts(list []int) []int {
+	var result []int
+	for _, v := range list {
+		if !contains(result, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
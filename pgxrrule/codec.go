@@ -0,0 +1,450 @@
+// Package pgxrrule maps the postgres-rrule composite types to rrule.RRule and
+// rrule.Set for pgx, so that they are sent in the binary format instead of
+// the text of their Value and Scan methods.
+package pgxrrule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/kiraxie/rrule-go"
+)
+
+// Names of the types of the postgres-rrule schema, see rrule.Migrations.
+const (
+	FreqTypeName          = "_rrule.freq"
+	DayTypeName           = "_rrule.day"
+	DayArrayTypeName      = "_rrule._day"
+	RRuleTypeName         = "_rrule.rrule"
+	RRuleSetTypeName      = "_rrule.rruleset"
+	RRuleSetArrayTypeName = "_rrule._rruleset"
+)
+
+// Register loads the postgres-rrule types from the database of conn into
+// its type map, _rrule.RRULE being scanned to rrule.RRule, _rrule.RRULESET to
+// rrule.Set and _rrule.RRULESET[] to []rrule.Set.
+func Register(ctx context.Context, conn *pgx.Conn) error {
+	for _, name := range []string{
+		FreqTypeName, DayTypeName, DayArrayTypeName, RRuleTypeName, RRuleSetTypeName, RRuleSetArrayTypeName,
+	} {
+		t, err := conn.LoadType(ctx, name)
+		if err != nil {
+			return fmt.Errorf("load %s: %w", name, err)
+		}
+		RegisterType(conn.TypeMap(), t)
+	}
+	return nil
+}
+
+// RegisterType registers t to m, the composite codecs of _rrule.RRULE and
+// _rrule.RRULESET being wrapped by RRuleCodec and SetCodec. The types must be
+// registered in the order of Register, a composite after its fields.
+func RegisterType(m *pgtype.Map, t *pgtype.Type) {
+	if codec, ok := t.Codec.(*pgtype.CompositeCodec); ok {
+		switch t.Name {
+		case RRuleTypeName:
+			t = &pgtype.Type{Name: t.Name, OID: t.OID, Codec: &RRuleCodec{codec}}
+		case RRuleSetTypeName:
+			t = &pgtype.Type{Name: t.Name, OID: t.OID, Codec: &SetCodec{codec}}
+		}
+	}
+	m.RegisterType(t)
+	switch t.Name {
+	case RRuleTypeName:
+		m.RegisterDefaultPgType(rrule.RRule{}, t.Name)
+	case RRuleSetTypeName:
+		m.RegisterDefaultPgType(rrule.Set{}, t.Name)
+	case RRuleSetArrayTypeName:
+		m.RegisterDefaultPgType([]rrule.Set{}, t.Name)
+	}
+}
+
+// RRuleCodec is the codec of _rrule.RRULE, it scans into *rrule.RRule and
+// encodes rrule.RRule and *rrule.RRule, other values are left to the
+// composite codec. Fields missing in the database, e.g. byeaster before its
+// migration, are NULL.
+type RRuleCodec struct {
+	*pgtype.CompositeCodec
+}
+
+func (c *RRuleCodec) PlanScan(m *pgtype.Map, oid uint32, format int16, target any) pgtype.ScanPlan {
+	if _, ok := target.(*rrule.RRule); !ok {
+		return c.CompositeCodec.PlanScan(m, oid, format, target)
+	}
+	fields := &rruleFields{}
+	next := c.CompositeCodec.PlanScan(m, oid, format, fields.targets(c.Fields))
+	if next == nil {
+		return nil
+	}
+	return &scanPlanRRule{fields: c.Fields, next: next}
+}
+
+func (c *RRuleCodec) PlanEncode(m *pgtype.Map, oid uint32, format int16, value any) pgtype.EncodePlan {
+	switch value.(type) {
+	case rrule.RRule, *rrule.RRule:
+	default:
+		return c.CompositeCodec.PlanEncode(m, oid, format, value)
+	}
+	next := c.CompositeCodec.PlanEncode(m, oid, format, pgtype.CompositeFields{})
+	if next == nil {
+		return nil
+	}
+	return &encodePlanRRule{fields: c.Fields, next: next}
+}
+
+type scanPlanRRule struct {
+	fields []pgtype.CompositeCodecField
+	next   pgtype.ScanPlan
+}
+
+func (plan *scanPlanRRule) Scan(src []byte, target any) error {
+	if src == nil {
+		return fmt.Errorf("%w: cannot scan NULL into %T", rrule.ErrInvalidRRuleFormat, target)
+	}
+	fields := &rruleFields{}
+	if err := plan.next.Scan(src, fields.targets(plan.fields)); err != nil {
+		return err
+	}
+	r, err := fields.rrule()
+	if err != nil {
+		return err
+	}
+	*target.(*rrule.RRule) = *r
+	return nil
+}
+
+type encodePlanRRule struct {
+	fields []pgtype.CompositeCodecField
+	next   pgtype.EncodePlan
+}
+
+func (plan *encodePlanRRule) Encode(value any, buf []byte) ([]byte, error) {
+	r, ok := value.(*rrule.RRule)
+	if !ok {
+		v := value.(rrule.RRule)
+		r = &v
+	}
+	if r == nil {
+		return nil, nil
+	}
+	f, err := newRRuleFields(r)
+	if err != nil {
+		return nil, err
+	}
+	return plan.next.Encode(f.values(plan.fields), buf)
+}
+
+// rruleFields are the fields of _rrule.RRULE, NULL being their zero value.
+type rruleFields struct {
+	freq, wkst      pgtype.Text
+	interval, count pgtype.Int4
+	until           pgtype.Timestamp
+	byday           []string
+
+	bysecond, byminute, byhour, bymonthday, byyearday, byweekno, bymonth, bysetpos, byeaster []int
+}
+
+var weekdays = []rrule.Weekday{
+	rrule.Monday, rrule.Tuesday, rrule.Wednesday, rrule.Thursday, rrule.Friday, rrule.Saturday, rrule.Sunday,
+}
+
+// newRRuleFields returns the fields of r as RRule.Value writes them. As
+// RRule.Value, it returns rrule.ErrNotRepresentable for the options that
+// _rrule.RRULE has no field for.
+func newRRuleFields(r *rrule.RRule) (*rruleFields, error) {
+	if err := r.CheckComposite(); err != nil {
+		return nil, err
+	}
+	option := r.Options
+	f := &rruleFields{
+		freq:       pgtype.Text{String: option.Freq.String(), Valid: true},
+		wkst:       pgtype.Text{String: option.Wkst.String(), Valid: true},
+		interval:   pgtype.Int4{Int32: int32(option.Interval), Valid: true},
+		count:      pgtype.Int4{Int32: int32(option.Count), Valid: option.Count != 0},
+		until:      pgtype.Timestamp{Time: option.Until.UTC(), Valid: !option.Until.IsZero()},
+		bysecond:   option.Bysecond,
+		byminute:   option.Byminute,
+		byhour:     option.Byhour,
+		bymonthday: option.Bymonthday,
+		byyearday:  option.Byyearday,
+		byweekno:   option.Byweekno,
+		bymonth:    option.Bymonth,
+		bysetpos:   option.Bysetpos,
+		byeaster:   option.Byeaster,
+	}
+	for _, wday := range option.Byweekday {
+		f.byday = append(f.byday, weekdays[wday.Day()].String())
+	}
+	return f, nil
+}
+
+// field returns the pointer to the field of the name, nil for an unknown
+// field.
+func (f *rruleFields) field(name string) any {
+	switch name {
+	case "freq":
+		return &f.freq
+	case "interval":
+		return &f.interval
+	case "count":
+		return &f.count
+	case "until":
+		return &f.until
+	case "bysecond":
+		return &f.bysecond
+	case "byminute":
+		return &f.byminute
+	case "byhour":
+		return &f.byhour
+	case "byday":
+		return &f.byday
+	case "bymonthday":
+		return &f.bymonthday
+	case "byyearday":
+		return &f.byyearday
+	case "byweekno":
+		return &f.byweekno
+	case "bymonth":
+		return &f.bymonth
+	case "bysetpos":
+		return &f.bysetpos
+	case "wkst":
+		return &f.wkst
+	case "byeaster":
+		return &f.byeaster
+	}
+	return nil
+}
+
+func (f *rruleFields) targets(fields []pgtype.CompositeCodecField) pgtype.CompositeFields {
+	targets := make(pgtype.CompositeFields, len(fields))
+	for i, field := range fields {
+		targets[i] = f.field(field.Name)
+	}
+	return targets
+}
+
+// values returns the values of the fields, an unknown field being NULL.
+func (f *rruleFields) values(fields []pgtype.CompositeCodecField) pgtype.CompositeFields {
+	values := make(pgtype.CompositeFields, len(fields))
+	for i, field := range fields {
+		switch v := f.field(field.Name).(type) {
+		case *pgtype.Text:
+			values[i] = *v
+		case *pgtype.Int4:
+			values[i] = *v
+		case *pgtype.Timestamp:
+			values[i] = *v
+		case *[]string:
+			values[i] = nullIfEmpty(*v)
+		case *[]int:
+			values[i] = nullIfEmpty(*v)
+		}
+	}
+	return values
+}
+
+// nullIfEmpty returns nil for an empty slice, so that it is written as NULL
+// like RRule.Value does.
+func nullIfEmpty[T any](s []T) any {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
+
+func (f *rruleFields) rrule() (*rrule.RRule, error) {
+	option := rrule.ROption{
+		Interval:   int(f.interval.Int32),
+		Count:      int(f.count.Int32),
+		Bysecond:   f.bysecond,
+		Byminute:   f.byminute,
+		Byhour:     f.byhour,
+		Bymonthday: f.bymonthday,
+		Byyearday:  f.byyearday,
+		Byweekno:   f.byweekno,
+		Bymonth:    f.bymonth,
+		Bysetpos:   f.bysetpos,
+		Byeaster:   f.byeaster,
+	}
+	if err := option.Freq.Parse(f.freq.String); err != nil {
+		return nil, err
+	}
+	if f.until.Valid {
+		option.Until = f.until.Time
+	}
+	if f.wkst.Valid {
+		if err := option.Wkst.Parse(f.wkst.String); err != nil {
+			return nil, err
+		}
+	}
+	option.Byweekday = make([]rrule.Weekday, len(f.byday))
+	for i, day := range f.byday {
+		if err := option.Byweekday[i].Parse(day); err != nil {
+			return nil, err
+		}
+	}
+	if len(option.Byweekday) == 0 {
+		option.Byweekday = nil
+	}
+	return rrule.NewRRule(option)
+}
+
+// SetCodec is the codec of _rrule.RRULESET, it scans into *rrule.Set and
+// encodes rrule.Set and *rrule.Set, other values are left to the composite
+// codec. The exrule of RRULESET is not supported, dtend is ignored.
+type SetCodec struct {
+	*pgtype.CompositeCodec
+}
+
+func (c *SetCodec) PlanScan(m *pgtype.Map, oid uint32, format int16, target any) pgtype.ScanPlan {
+	if _, ok := target.(*rrule.Set); !ok {
+		return c.CompositeCodec.PlanScan(m, oid, format, target)
+	}
+	fields := &setFields{}
+	next := c.CompositeCodec.PlanScan(m, oid, format, fields.targets(c.Fields))
+	if next == nil {
+		return nil
+	}
+	return &scanPlanSet{fields: c.Fields, next: next}
+}
+
+func (c *SetCodec) PlanEncode(m *pgtype.Map, oid uint32, format int16, value any) pgtype.EncodePlan {
+	switch value.(type) {
+	case rrule.Set, *rrule.Set:
+	default:
+		return c.CompositeCodec.PlanEncode(m, oid, format, value)
+	}
+	next := c.CompositeCodec.PlanEncode(m, oid, format, pgtype.CompositeFields{})
+	if next == nil {
+		return nil
+	}
+	return &encodePlanSet{fields: c.Fields, next: next}
+}
+
+type scanPlanSet struct {
+	fields []pgtype.CompositeCodecField
+	next   pgtype.ScanPlan
+}
+
+func (plan *scanPlanSet) Scan(src []byte, target any) error {
+	if src == nil {
+		return fmt.Errorf("%w: cannot scan NULL into %T", rrule.ErrInvalidRRuleFormat, target)
+	}
+	fields := &setFields{}
+	if err := plan.next.Scan(src, fields.targets(plan.fields)); err != nil {
+		return err
+	}
+	if fields.exrule != nil {
+		return fmt.Errorf("%w: exrule is not supported", rrule.ErrInvalidRRuleFormat)
+	}
+	set := target.(*rrule.Set)
+	*set = rrule.Set{}
+	if fields.dtstart.Valid {
+		set.DTStart(fields.dtstart.Time)
+	}
+	if fields.rrule != nil {
+		// The scanned rule has no DTSTART, it defaulted to the current time.
+		if !fields.dtstart.Valid {
+			return fmt.Errorf("%w: rrule without dtstart", rrule.ErrInvalidRRuleFormat)
+		}
+		set.RRule(fields.rrule.WithDTStart(fields.dtstart.Time))
+	}
+	set.SetRDates(fields.rdate)
+	set.SetExDates(fields.exdate)
+	return nil
+}
+
+type encodePlanSet struct {
+	fields []pgtype.CompositeCodecField
+	next   pgtype.EncodePlan
+}
+
+func (plan *encodePlanSet) Encode(value any, buf []byte) ([]byte, error) {
+	set, ok := value.(*rrule.Set)
+	if !ok {
+		v := value.(rrule.Set)
+		set = &v
+	}
+	if set == nil {
+		return nil, nil
+	}
+	// TIMESTAMP discards the time zone, the times are written in UTC like
+	// rrule.Set.Value does.
+	if err := set.CheckComposite(); err != nil {
+		return nil, err
+	}
+	f := &setFields{
+		dtstart: pgtype.Timestamp{Time: set.GetDTStart().UTC(), Valid: !set.GetDTStart().IsZero()},
+		rrule:   set.GetRRule(),
+		rdate:   utcTimes(set.GetRDate()),
+		exdate:  utcTimes(set.GetExDate()),
+	}
+	return plan.next.Encode(f.values(plan.fields), buf)
+}
+
+// utcTimes returns the times in UTC.
+func utcTimes(times []time.Time) []time.Time {
+	utc := make([]time.Time, len(times))
+	for i, t := range times {
+		utc[i] = t.UTC()
+	}
+	return utc
+}
+
+// setFields are the fields of _rrule.RRULESET, NULL being their zero value.
+type setFields struct {
+	dtstart, dtend pgtype.Timestamp
+	rrule, exrule  *rrule.RRule
+	rdate, exdate  []time.Time
+}
+
+// field returns the pointer to the field of the name, nil for an unknown
+// field.
+func (f *setFields) field(name string) any {
+	switch name {
+	case "dtstart":
+		return &f.dtstart
+	case "dtend":
+		return &f.dtend
+	case "rrule":
+		return &f.rrule
+	case "exrule":
+		return &f.exrule
+	case "rdate":
+		return &f.rdate
+	case "exdate":
+		return &f.exdate
+	}
+	return nil
+}
+
+func (f *setFields) targets(fields []pgtype.CompositeCodecField) pgtype.CompositeFields {
+	targets := make(pgtype.CompositeFields, len(fields))
+	for i, field := range fields {
+		targets[i] = f.field(field.Name)
+	}
+	return targets
+}
+
+// values returns the values of the fields, an unknown field being NULL.
+func (f *setFields) values(fields []pgtype.CompositeCodecField) pgtype.CompositeFields {
+	values := make(pgtype.CompositeFields, len(fields))
+	for i, field := range fields {
+		switch v := f.field(field.Name).(type) {
+		case *pgtype.Timestamp:
+			values[i] = *v
+		case **rrule.RRule:
+			if *v != nil {
+				values[i] = *v
+			}
+		case *[]time.Time:
+			values[i] = nullIfEmpty(*v)
+		}
+	}
+	return values
+}
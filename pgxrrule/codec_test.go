@@ -0,0 +1,329 @@
+package pgxrrule
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiraxie/rrule-go"
+)
+
+// OIDs of the postgres-rrule types in the tests, as a database assigns them.
+const (
+	freqOID uint32 = 90001 + iota
+	dayOID
+	dayArrayOID
+	rruleOID
+	rrulesetOID
+	rrulesetArrayOID
+)
+
+// newTypeMap returns a map with the types Register loads from a database
+// migrated with rrule.Migrations, without byeaster for legacy.
+func newTypeMap(legacy bool) *pgtype.Map {
+	m := pgtype.NewMap()
+	register := func(name string, oid uint32, codec pgtype.Codec) {
+		RegisterType(m, &pgtype.Type{Name: name, OID: oid, Codec: codec})
+	}
+	typeOf := func(oid uint32) *pgtype.Type {
+		t, _ := m.TypeForOID(oid)
+		return t
+	}
+	register(FreqTypeName, freqOID, &pgtype.EnumCodec{})
+	register(DayTypeName, dayOID, &pgtype.EnumCodec{})
+	register(DayArrayTypeName, dayArrayOID, &pgtype.ArrayCodec{ElementType: typeOf(dayOID)})
+
+	fields := []pgtype.CompositeCodecField{{Name: "freq", Type: typeOf(freqOID)}}
+	for _, name := range []string{"interval", "count"} {
+		fields = append(fields, pgtype.CompositeCodecField{Name: name, Type: typeOf(pgtype.Int4OID)})
+	}
+	fields = append(fields, pgtype.CompositeCodecField{Name: "until", Type: typeOf(pgtype.TimestampOID)})
+	for _, name := range []string{"bysecond", "byminute", "byhour"} {
+		fields = append(fields, pgtype.CompositeCodecField{Name: name, Type: typeOf(pgtype.Int4ArrayOID)})
+	}
+	fields = append(fields, pgtype.CompositeCodecField{Name: "byday", Type: typeOf(dayArrayOID)})
+	for _, name := range []string{"bymonthday", "byyearday", "byweekno", "bymonth", "bysetpos"} {
+		fields = append(fields, pgtype.CompositeCodecField{Name: name, Type: typeOf(pgtype.Int4ArrayOID)})
+	}
+	fields = append(fields, pgtype.CompositeCodecField{Name: "wkst", Type: typeOf(dayOID)})
+	if !legacy {
+		fields = append(fields, pgtype.CompositeCodecField{Name: "byeaster", Type: typeOf(pgtype.Int4ArrayOID)})
+	}
+	register(RRuleTypeName, rruleOID, &pgtype.CompositeCodec{Fields: fields})
+
+	register(RRuleSetTypeName, rrulesetOID, &pgtype.CompositeCodec{Fields: []pgtype.CompositeCodecField{
+		{Name: "dtstart", Type: typeOf(pgtype.TimestampOID)},
+		{Name: "dtend", Type: typeOf(pgtype.TimestampOID)},
+		{Name: "rrule", Type: typeOf(rruleOID)},
+		{Name: "exrule", Type: typeOf(rruleOID)},
+		{Name: "rdate", Type: typeOf(pgtype.TimestampArrayOID)},
+		{Name: "exdate", Type: typeOf(pgtype.TimestampArrayOID)},
+	}})
+	register(RRuleSetArrayTypeName, rrulesetArrayOID, &pgtype.ArrayCodec{ElementType: typeOf(rrulesetOID)})
+	return m
+}
+
+// field is a field of a binary composite or an element of a binary array,
+// data being nil for NULL.
+type field struct {
+	oid  uint32
+	data []byte
+}
+
+func composite(fields ...field) []byte {
+	b := binary.BigEndian.AppendUint32(nil, uint32(len(fields)))
+	for _, f := range fields {
+		b = binary.BigEndian.AppendUint32(b, f.oid)
+		b = appendData(b, f.data)
+	}
+	return b
+}
+
+func array(elemOID uint32, elems ...[]byte) []byte {
+	hasNull := uint32(0)
+	for _, e := range elems {
+		if e == nil {
+			hasNull = 1
+		}
+	}
+	b := binary.BigEndian.AppendUint32(nil, 1)
+	b = binary.BigEndian.AppendUint32(b, hasNull)
+	b = binary.BigEndian.AppendUint32(b, elemOID)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(elems)))
+	b = binary.BigEndian.AppendUint32(b, 1)
+	for _, e := range elems {
+		b = appendData(b, e)
+	}
+	return b
+}
+
+func appendData(b, data []byte) []byte {
+	if data == nil {
+		return binary.BigEndian.AppendUint32(b, 0xffffffff)
+	}
+	b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+	return append(b, data...)
+}
+
+func int4(v int32) []byte {
+	return binary.BigEndian.AppendUint32(nil, uint32(v))
+}
+
+func int4s(values ...int32) []byte {
+	elems := make([][]byte, len(values))
+	for i, v := range values {
+		elems[i] = int4(v)
+	}
+	return array(pgtype.Int4OID, elems...)
+}
+
+// timestamp encodes t as microseconds since 2000-01-01.
+func timestamp(t time.Time) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(t.Sub(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).Microseconds()))
+}
+
+// weeklyPayload is (WEEKLY,2,NULL,"2024-03-01 09:00:00",,,"{9}","{MO,FR}",,,,,,SU,NULL).
+func weeklyPayload(legacy bool) []byte {
+	fields := []field{
+		{freqOID, []byte("WEEKLY")},
+		{pgtype.Int4OID, int4(2)},
+		{pgtype.Int4OID, nil},
+		{pgtype.TimestampOID, timestamp(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC))},
+		{pgtype.Int4ArrayOID, nil},
+		{pgtype.Int4ArrayOID, nil},
+		{pgtype.Int4ArrayOID, int4s(9)},
+		{dayArrayOID, array(dayOID, []byte("MO"), []byte("FR"))},
+		{pgtype.Int4ArrayOID, nil},
+		{pgtype.Int4ArrayOID, nil},
+		{pgtype.Int4ArrayOID, nil},
+		{pgtype.Int4ArrayOID, nil},
+		{pgtype.Int4ArrayOID, nil},
+		{dayOID, []byte("SU")},
+	}
+	if !legacy {
+		fields = append(fields, field{pgtype.Int4ArrayOID, nil})
+	}
+	return composite(fields...)
+}
+
+func TestRRuleCodec(t *testing.T) {
+	t.Parallel()
+	for _, legacy := range []bool{false, true} {
+		m := newTypeMap(legacy)
+		var r rrule.RRule
+		assert.NoError(t, m.Scan(rruleOID, pgtype.BinaryFormatCode, weeklyPayload(legacy), &r))
+		assert.Equal(t, rrule.Weekly, r.Options.Freq)
+		assert.Equal(t, 2, r.Options.Interval)
+		assert.Equal(t, time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), r.GetUntil())
+		assert.Equal(t, []int{9}, r.Options.Byhour)
+		assert.Equal(t, []rrule.Weekday{rrule.Monday, rrule.Friday}, r.Options.Byweekday)
+		assert.Equal(t, rrule.Sunday, r.Options.Wkst)
+		assert.Empty(t, r.Options.Byeaster)
+
+		encoded, err := m.Encode(rruleOID, pgtype.BinaryFormatCode, &r, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, weeklyPayload(legacy), encoded)
+		encoded, err = m.Encode(rruleOID, pgtype.BinaryFormatCode, r, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, weeklyPayload(legacy), encoded)
+	}
+
+	m := newTypeMap(false)
+	var r *rrule.RRule
+	assert.NoError(t, m.Scan(rruleOID, pgtype.BinaryFormatCode, nil, &r))
+	assert.Nil(t, r)
+	assert.Error(t, m.Scan(rruleOID, pgtype.BinaryFormatCode, nil, &rrule.RRule{}))
+	encoded, err := m.Encode(rruleOID, pgtype.BinaryFormatCode, r, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, encoded)
+
+	// The text format goes through the composite codec too.
+	var scanned rrule.RRule
+	assert.NoError(t, m.Scan(rruleOID, pgtype.TextFormatCode, []byte(`(DAILY,1,5,,,,,,,,,,,MO,"{-2}")`), &scanned))
+	assert.Equal(t, rrule.Daily, scanned.Options.Freq)
+	assert.Equal(t, 5, scanned.Options.Count)
+	assert.Equal(t, []int{-2}, scanned.Options.Byeaster)
+
+	// The options _rrule.RRULE has no field for are not dropped.
+	for _, rule := range []string{
+		"RRULE:RSCALE=HEBREW;FREQ=YEARLY;BYMONTH=5L",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=31;SKIP=BACKWARD",
+		"RRULE:FREQ=MONTHLY;BYDAY=-1FR",
+		"X-BUSINESSDAY-RRULE:FREQ=MONTHLY;BYBUSINESSDAY=-1",
+	} {
+		r, err := rrule.StrToRRule(rule)
+		assert.NoError(t, err, rule)
+		// pgx wraps the errors of the encode plans as text.
+		_, err = m.Encode(rruleOID, pgtype.BinaryFormatCode, r, nil)
+		assert.ErrorContains(t, err, rrule.ErrNotRepresentable.Error(), rule)
+	}
+}
+
+func setPayload(dtstart time.Time, rule []byte, rdates ...time.Time) []byte {
+	rdate := make([][]byte, len(rdates))
+	for i, dt := range rdates {
+		rdate[i] = timestamp(dt)
+	}
+	fields := []field{
+		{pgtype.TimestampOID, timestamp(dtstart)},
+		{pgtype.TimestampOID, nil},
+		{rruleOID, rule},
+		{rruleOID, nil},
+		{pgtype.TimestampArrayOID, nil},
+		{pgtype.TimestampArrayOID, nil},
+	}
+	if len(rdates) != 0 {
+		fields[4].data = array(pgtype.TimestampOID, rdate...)
+	}
+	return composite(fields...)
+}
+
+func TestSetCodec(t *testing.T) {
+	t.Parallel()
+	m := newTypeMap(false)
+	dtstart := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	rdate := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	payload := setPayload(dtstart, weeklyPayload(false), rdate)
+
+	var set rrule.Set
+	assert.NoError(t, m.Scan(rrulesetOID, pgtype.BinaryFormatCode, payload, &set))
+	assert.Equal(t, dtstart, set.GetDTStart())
+	assert.Equal(t, []time.Time{rdate}, set.GetRDate())
+	assert.Empty(t, set.GetExDate())
+	got := set.Between(dtstart, dtstart.AddDate(0, 0, 7), true)
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+		rdate,
+		time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC),
+	}, got)
+
+	encoded, err := m.Encode(rrulesetOID, pgtype.BinaryFormatCode, &set, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, encoded)
+
+	// A set of dates only.
+	payload = setPayload(dtstart, nil, rdate)
+	assert.NoError(t, m.Scan(rrulesetOID, pgtype.BinaryFormatCode, payload, &set))
+	assert.Nil(t, set.GetRRule())
+	assert.Equal(t, []time.Time{rdate}, set.All())
+	encoded, err = m.Encode(rrulesetOID, pgtype.BinaryFormatCode, set, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, encoded)
+
+	exrule := composite(
+		field{pgtype.TimestampOID, timestamp(dtstart)},
+		field{pgtype.TimestampOID, nil},
+		field{rruleOID, nil},
+		field{rruleOID, weeklyPayload(false)},
+		field{pgtype.TimestampArrayOID, nil},
+		field{pgtype.TimestampArrayOID, nil},
+	)
+	assert.ErrorIs(t, m.Scan(rrulesetOID, pgtype.BinaryFormatCode, exrule, &set), rrule.ErrInvalidRRuleFormat)
+
+	// A rule needs the dtstart of the set, it would default to the current time.
+	noDTStart := composite(
+		field{pgtype.TimestampOID, nil},
+		field{pgtype.TimestampOID, nil},
+		field{rruleOID, weeklyPayload(false)},
+		field{rruleOID, nil},
+		field{pgtype.TimestampArrayOID, nil},
+		field{pgtype.TimestampArrayOID, nil},
+	)
+	assert.ErrorIs(t, m.Scan(rrulesetOID, pgtype.BinaryFormatCode, noDTStart, &set), rrule.ErrInvalidRRuleFormat)
+	noDTStart = composite(
+		field{pgtype.TimestampOID, nil},
+		field{pgtype.TimestampOID, nil},
+		field{rruleOID, nil},
+		field{rruleOID, nil},
+		field{pgtype.TimestampArrayOID, array(pgtype.TimestampOID, timestamp(rdate))},
+		field{pgtype.TimestampArrayOID, nil},
+	)
+	assert.NoError(t, m.Scan(rrulesetOID, pgtype.BinaryFormatCode, noDTStart, &set))
+	assert.Equal(t, []time.Time{rdate}, set.All())
+
+	// The times are written in UTC, a rule of another TZID is rejected.
+	paris, err := time.LoadLocation("Europe/Paris")
+	assert.NoError(t, err)
+	set = rrule.Set{}
+	set.DTStart(dtstart.In(paris))
+	set.RDate(rdate.In(paris))
+	encoded, err = m.Encode(rrulesetOID, pgtype.BinaryFormatCode, set, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, setPayload(dtstart, nil, rdate), encoded)
+	r, err := rrule.NewRRule(rrule.ROption{Freq: rrule.Daily, Count: 2})
+	assert.NoError(t, err)
+	set.RRule(r)
+	_, err = m.Encode(rrulesetOID, pgtype.BinaryFormatCode, set, nil)
+	assert.ErrorContains(t, err, rrule.ErrNotRepresentable.Error())
+	// Etc/UTC has the wall clock of UTC.
+	utc, err := time.LoadLocation("Etc/UTC")
+	assert.NoError(t, err)
+	set.DTStart(dtstart.In(utc))
+	_, err = m.Encode(rrulesetOID, pgtype.BinaryFormatCode, set, nil)
+	assert.NoError(t, err)
+}
+
+func TestSetArrayCodec(t *testing.T) {
+	t.Parallel()
+	m := newTypeMap(false)
+	dtstart := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	payload := array(rrulesetOID,
+		setPayload(dtstart, weeklyPayload(false)),
+		setPayload(dtstart.AddDate(0, 1, 0), nil, dtstart.AddDate(0, 1, 1)),
+	)
+
+	var sets []rrule.Set
+	assert.NoError(t, m.Scan(rrulesetArrayOID, pgtype.BinaryFormatCode, payload, &sets))
+	if assert.Len(t, sets, 2) {
+		assert.Equal(t, dtstart, sets[0].GetDTStart())
+		assert.NotNil(t, sets[0].GetRRule())
+		assert.Equal(t, []time.Time{dtstart.AddDate(0, 1, 1)}, sets[1].All())
+	}
+
+	encoded, err := m.Encode(rrulesetArrayOID, pgtype.BinaryFormatCode, sets, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, payload, encoded)
+}
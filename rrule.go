@@ -9,7 +9,7 @@ import (
 )
 
 // ROption offers options to construct a RRule instance.
-// For performance, it is strongly recommended providing explicit ROption.Dtstart, which defaults to `time.Now().UTC().Truncate(time.Second)`.
+// For performance, it is strongly recommended providing explicit ROption.Dtstart, which defaults to `Clock.Now().UTC().Truncate(time.Second)`.
 type ROption struct {
 	Freq       Frequency
 	Dtstart    time.Time
@@ -27,10 +27,32 @@ type ROption struct {
 	Byminute   []int
 	Bysecond   []int
 	Byeaster   []int
+	// Rscale names the calendar system of the rule as of RFC 7529, see
+	// RegisterCalendarSystem. It defaults to the Gregorian calendar.
+	Rscale string
+	// Skip tells how to handle invalid dates, such as a leap month in a
+	// common year. It defaults to Omit.
+	Skip Skip
+	// Byleapmonth lists the leap months of BYMONTH, e.g. 5 for "5L".
+	Byleapmonth []int
+	// Bybusinessday selects the nth business day of each period among the
+	// days matched by the other rule parts, -1 being the last one. It is
+	// written as the X-BUSINESSDAY-RRULE property, see StrToRRuleSet.
+	Bybusinessday []int
+	// BusinessCalendar defines the business days of Bybusinessday, it
+	// defaults to a calendar of Saturday and Sunday weekends.
+	BusinessCalendar *Calendar
+	// Clock gives the default of Dtstart, it defaults to SystemClock.
+	Clock Clock
 }
 
 // RRule offers a small, complete, and very fast, implementation of the recurrence rules
-// documented in the iCalendar RFC, including support for caching of results.
+// documented in the iCalendar RFC, including support for caching of results
+// with EnableCache.
+//
+// An RRule is safe for concurrent use by multiple goroutines: iterating and
+// querying it never modify it. DTStart, Until and BusinessCalendar do, so a
+// shared RRule must be derived with WithDTStart and WithUntil instead.
 type RRule struct {
 	OrigOptions             ROption
 	Options                 ROption
@@ -51,8 +73,15 @@ type RRule struct {
 	byminute                []int
 	bysecond                []int
 	byeaster                []int
+	bybusinessday           []int
+	businessCalendar        *Calendar
+	calendar                CalendarSystem
+	skip                    Skip
 	timeset                 []time.Time
-	len                     int
+	cache                   *occurrenceCache
+	// version counts the modifications of the rule, so that the cache of a
+	// Set holding it is emptied.
+	version uint64
 }
 
 // NewRRule construct a new RRule instance
@@ -60,6 +89,12 @@ func NewRRule(arg ROption) (*RRule, error) {
 	if err := validateBounds(arg); err != nil {
 		return nil, err
 	}
+	if err := validateRscale(arg); err != nil {
+		return nil, err
+	}
+	if err := validateBusinessDay(arg); err != nil {
+		return nil, err
+	}
 	r := buildRRule(arg)
 	return &r, nil
 }
@@ -83,7 +118,7 @@ func buildRRule(arg ROption) RRule {
 
 	// DTSTART default to now
 	if arg.Dtstart.IsZero() {
-		arg.Dtstart = time.Now().UTC()
+		arg.Dtstart = clockOrSystem(arg.Clock).Now().UTC()
 	}
 	arg.Dtstart = arg.Dtstart.Truncate(time.Second)
 	r.dtstart = arg.Dtstart
@@ -100,25 +135,48 @@ func buildRRule(arg ROption) RRule {
 	r.wkst = arg.Wkst.weekday
 	r.bysetpos = arg.Bysetpos
 
+	// RSCALE and SKIP, validated by NewRRule
+	r.calendar, _ = calendarSystemOf(arg.Rscale)
+	r.skip = arg.Skip
+
 	if len(arg.Byweekno) == 0 &&
 		len(arg.Byyearday) == 0 &&
 		len(arg.Bymonthday) == 0 &&
 		len(arg.Byweekday) == 0 &&
-		len(arg.Byeaster) == 0 {
+		len(arg.Byeaster) == 0 &&
+		len(arg.Bybusinessday) == 0 {
+		month, day := CalendarMonth{Month: int(r.dtstart.Month())}, r.dtstart.Day()
+		if r.calendar != nil {
+			var cyear, cmonth int
+			cyear, cmonth, day = r.calendar.FromGregorian(r.dtstart.Date())
+			month = r.calendar.Months(cyear)[cmonth-1]
+		}
 		if r.freq == Yearly {
-			if len(arg.Bymonth) == 0 {
-				arg.Bymonth = []int{int(r.dtstart.Month())}
+			if len(arg.Bymonth) == 0 && len(arg.Byleapmonth) == 0 {
+				if month.Leap {
+					arg.Byleapmonth = []int{month.Month}
+				} else {
+					arg.Bymonth = []int{month.Month}
+				}
 			}
-			arg.Bymonthday = []int{r.dtstart.Day()}
+			arg.Bymonthday = []int{day}
 		} else if r.freq == Monthly {
-			arg.Bymonthday = []int{r.dtstart.Day()}
+			arg.Bymonthday = []int{day}
 		} else if r.freq == Weekly {
 			arg.Byweekday = []Weekday{{weekday: toPyWeekday(r.dtstart.Weekday())}}
 		}
 	}
-	r.bymonth = arg.Bymonth
+	r.bymonth = append([]int(nil), arg.Bymonth...)
+	for _, month := range arg.Byleapmonth {
+		r.bymonth = append(r.bymonth, -month)
+	}
 	r.byyearday = arg.Byyearday
 	r.byeaster = arg.Byeaster
+	r.bybusinessday = arg.Bybusinessday
+	r.businessCalendar = arg.BusinessCalendar
+	if len(r.bybusinessday) != 0 && r.businessCalendar == nil {
+		r.businessCalendar = NewCalendar()
+	}
 	for _, mday := range arg.Bymonthday {
 		if mday > 0 {
 			r.bymonthday = append(r.bymonthday, mday)
@@ -146,14 +204,16 @@ func buildRRule(arg ROption) RRule {
 			r.byminute = []int{r.dtstart.Minute()}
 		}
 	} else {
-		r.byminute = arg.Byminute
+		// Sorted so that the time sets of HOURLY and MINUTELY periods are
+		// generated in order.
+		r.byminute = sortedInts(arg.Byminute)
 	}
 	if len(arg.Bysecond) == 0 {
 		if r.freq < Secondly {
 			r.bysecond = []int{r.dtstart.Second()}
 		}
 	} else {
-		r.bysecond = arg.Bysecond
+		r.bysecond = sortedInts(arg.Bysecond)
 	}
 
 	// Reset the timeset value
@@ -193,7 +253,9 @@ func validateBounds(arg ROption) error {
 		{arg.Byyearday, "byyearday", []int{1, 366}, true},
 		{arg.Byweekno, "byweekno", []int{1, 53}, true},
 		{arg.Bymonth, "bymonth", []int{1, 12}, false},
+		{arg.Byleapmonth, "byleapmonth", []int{1, 12}, false},
 		{arg.Bysetpos, "bysetpos", []int{1, 366}, true},
+		{arg.Bybusinessday, "bybusinessday", []int{1, 366}, true},
 	}
 
 	checkBounds := func(param string, value int, bounds []int, plusMinus bool) error {
@@ -230,6 +292,39 @@ func validateBounds(arg ROption) error {
 	return nil
 }
 
+// validateRscale checks the calendar system of the RRule is registered and
+// supports the given options.
+func validateRscale(arg ROption) error {
+	cs, err := calendarSystemOf(arg.Rscale)
+	if err != nil {
+		return err
+	}
+	if cs != nil && len(arg.Byeaster) != 0 {
+		return fmt.Errorf("%w: byeaster requires the Gregorian calendar", ErrUnsupportedRscale)
+	}
+	if arg.Skip < Omit || arg.Skip > Forward {
+		return fmt.Errorf("%w: %d", ErrInvalidSkip, arg.Skip)
+	}
+
+	return nil
+}
+
+// validateBusinessDay checks Bybusinessday is used with a period of days
+// and without BYSETPOS, which also selects within the period.
+func validateBusinessDay(arg ROption) error {
+	if len(arg.Bybusinessday) == 0 {
+		return nil
+	}
+	if arg.Freq > Daily {
+		return fmt.Errorf("%w: bybusinessday requires a daily or longer period", ErrInvalidFreq)
+	}
+	if len(arg.Bysetpos) != 0 {
+		return fmt.Errorf("%w: bybusinessday and bysetpos cannot be used together", ErrInvalidRRuleFormat)
+	}
+
+	return nil
+}
+
 type iterInfo struct {
 	rrule       *RRule
 	lastyear    int
@@ -246,33 +341,127 @@ type iterInfo struct {
 	wnomask     []int
 	nwdaymask   []int
 	eastermask  []int
+	bymonth     []int
+	monthbuf    []int
+	months      []CalendarMonth
+	monthsyear  int
+}
+
+// yearStart returns the first day of a year of the rule's calendar system.
+func (info *iterInfo) yearStart(year int) time.Time {
+	if info.rrule.calendar == nil {
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, info.rrule.dtstart.Location())
+	}
+	y, m, d := info.rrule.calendar.YearStart(year)
+	return time.Date(y, m, d, 0, 0, 0, 0, info.rrule.dtstart.Location())
+}
+
+// yearLen returns the number of days in a year of the rule's calendar system.
+func (info *iterInfo) yearLen(year int) int {
+	if info.rrule.calendar == nil {
+		return 365 + isLeap(year)
+	}
+	yearlen := 0
+	for _, month := range info.monthsOf(year) {
+		yearlen += month.Days
+	}
+	return yearlen
+}
+
+// monthsIn returns the number of months in a year of the rule's calendar system.
+func (info *iterInfo) monthsIn(year int) int {
+	if info.rrule.calendar == nil {
+		return 12
+	}
+	return len(info.monthsOf(year))
+}
+
+// daysIn returns the number of days in the ordinal month of a year of the
+// rule's calendar system.
+func (info *iterInfo) daysIn(month time.Month, year int) int {
+	if info.rrule.calendar == nil {
+		return daysIn(month, year)
+	}
+	return info.monthsOf(year)[month-1].Days
+}
+
+func (info *iterInfo) monthsOf(year int) []CalendarMonth {
+	if year == info.monthsyear && info.months != nil {
+		return info.months
+	}
+	return info.rrule.calendar.Months(year)
+}
+
+// rebuildMonths applies the SKIP rule to the leap months of BYMONTH absent
+// from the current year, RFC 7529 section 4.2.
+func (info *iterInfo) rebuildMonths() {
+	info.bymonth = info.rrule.bymonth
+	if info.rrule.skip == Omit {
+		return
+	}
+	for i, label := range info.rrule.bymonth {
+		if label > 0 || contains(info.mmask[:info.yearlen], label) {
+			continue
+		}
+		if sameSlice(info.bymonth, info.rrule.bymonth) {
+			info.monthbuf = append(info.monthbuf[:0], info.rrule.bymonth...)
+			info.bymonth = info.monthbuf
+		}
+		switch info.rrule.skip {
+		case Backward:
+			info.bymonth[i] = -label
+		case Forward:
+			// The month following "mL" is m+1, or none in the last month.
+			info.bymonth[i] = 0
+			for k := 1; k < len(info.mrange)-1; k++ {
+				if info.mmask[info.mrange[k-1]] == -label {
+					info.bymonth[i] = info.mmask[info.mrange[k]]
+				}
+			}
+		}
+	}
+}
+
+func sameSlice(a, b []int) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
 }
 
 func (info *iterInfo) rebuild(year int, month time.Month) {
 	// Every mask is 7 days longer to handle cross-year weekly periods.
 	if year != info.lastyear {
-		info.yearlen = 365 + isLeap(year)
-		info.nextyearlen = 365 + isLeap(year+1)
-		info.firstyday = time.Date(
-			year, time.January, 1, 0, 0, 0, 0,
-			info.rrule.dtstart.Location())
-		info.yearweekday = toPyWeekday(info.firstyday.Weekday())
-		info.wdaymask = maskDay[info.yearweekday:]
-		if info.yearlen == 365 {
-			info.mmask = mask365
-			info.mdaymask = mask365day
-			info.nmdaymask = mask365monthDay
-			info.mrange = range365
+		if info.rrule.calendar == nil {
+			info.yearlen = 365 + isLeap(year)
+			info.nextyearlen = 365 + isLeap(year+1)
+			info.firstyday = info.yearStart(year)
+			if info.yearlen == 365 {
+				info.mmask = mask365
+				info.mdaymask = mask365day
+				info.nmdaymask = mask365monthDay
+				info.mrange = range365
+			} else {
+				info.mmask = mask366
+				info.mdaymask = mask366day
+				info.nmdaymask = mask366monthDay
+				info.mrange = range366
+			}
 		} else {
-			info.mmask = mask366
-			info.mdaymask = mask366day
-			info.nmdaymask = mask366monthDay
-			info.mrange = range366
+			cy := newCalendarYear(info.rrule.calendar, year, info.rrule.dtstart.Location())
+			info.yearlen = cy.yearlen
+			info.nextyearlen = info.yearLen(year + 1)
+			info.firstyday = cy.firstyday
+			info.months, info.monthsyear = cy.months, year
+			info.mmask = cy.mmask
+			info.mdaymask = cy.mdaymask
+			info.nmdaymask = cy.nmdaymask
+			info.mrange = cy.mrange
 		}
+		info.yearweekday = toPyWeekday(info.firstyday.Weekday())
+		info.wdaymask = maskDay[info.yearweekday:]
+		info.rebuildMonths()
 		if len(info.rrule.byweekno) == 0 {
 			info.wnomask = nil
 		} else {
-			info.wnomask = make([]int, info.yearlen+7)
+			info.wnomask = resetInts(info.wnomask, info.yearlen+7)
 			firstwkst := pymod(7-info.yearweekday+info.rrule.wkst, 7)
 			no1wkst := firstwkst
 			var wyearlen int
@@ -338,11 +527,9 @@ func (info *iterInfo) rebuild(year int, month time.Month) {
 				// this year.
 				var lnumweeks int
 				if !contains(info.rrule.byweekno, -1) {
-					lyearweekday := toPyWeekday(time.Date(
-						year-1, 1, 1, 0, 0, 0, 0,
-						info.rrule.dtstart.Location()).Weekday())
+					lyearweekday := toPyWeekday(info.yearStart(year - 1).Weekday())
 					lno1wkst := pymod(7-lyearweekday+info.rrule.wkst, 7)
-					lyearlen := 365 + isLeap(year-1)
+					lyearlen := info.yearLen(year - 1)
 					if lno1wkst >= 4 {
 						lno1wkst = 0
 						lnumweeks = 52 + pymod(lyearlen+pymod(lyearweekday-info.rrule.wkst, 7), 7)/4
@@ -361,44 +548,26 @@ func (info *iterInfo) rebuild(year int, month time.Month) {
 		}
 	}
 	if len(info.rrule.bydays) != 0 && (month != info.lastmonth || year != info.lastyear) {
-		var ranges [][]int
-		if info.rrule.freq == Yearly {
-			if len(info.rrule.bymonth) != 0 {
-				for _, month := range info.rrule.bymonth {
-					ranges = append(ranges, info.mrange[month-1:month+1])
-				}
-			} else {
-				ranges = [][]int{{0, info.yearlen}}
-			}
-		} else if info.rrule.freq == Monthly {
-			ranges = [][]int{info.mrange[month-1 : month+1]}
-		}
-		if len(ranges) != 0 {
+		if info.rrule.freq == Yearly || info.rrule.freq == Monthly {
 			// Weekly frequency won't get here, so we may not
 			// care about cross-year weekly periods.
-			info.nwdaymask = make([]int, info.yearlen)
-			for _, x := range ranges {
-				first, last := x[0], x[1]
-				last--
-				for _, y := range info.rrule.bydays {
-					wday, n := y.weekday, y.n
-					var i int
-					if n < 0 {
-						i = last + (n+1)*7
-						i -= pymod(info.wdaymask[i]-wday, 7)
-					} else {
-						i = first + (n-1)*7
-						i += pymod(7-info.wdaymask[i]+wday, 7)
-					}
-					if first <= i && i <= last {
-						info.nwdaymask[i] = 1
+			info.nwdaymask = resetInts(info.nwdaymask, info.yearlen)
+			switch {
+			case info.rrule.freq == Monthly:
+				info.fillNthWeekdays(info.mrange[month-1], info.mrange[month])
+			case len(info.bymonth) != 0:
+				for month := 1; month < len(info.mrange); month++ {
+					if contains(info.bymonth, info.mmask[info.mrange[month-1]]) {
+						info.fillNthWeekdays(info.mrange[month-1], info.mrange[month])
 					}
 				}
+			default:
+				info.fillNthWeekdays(0, info.yearlen)
 			}
 		}
 	}
 	if len(info.rrule.byeaster) != 0 {
-		info.eastermask = make([]int, info.yearlen+7)
+		info.eastermask = resetInts(info.eastermask, info.yearlen+7)
 		eyday := easter(year).YearDay() - 1
 		for _, offset := range info.rrule.byeaster {
 			info.eastermask[eyday+offset] = 1
@@ -408,6 +577,43 @@ func (info *iterInfo) rebuild(year int, month time.Month) {
 	info.lastmonth = month
 }
 
+// fillNthWeekdays marks in nwdaymask the nth weekdays of BYDAY within the
+// days [first, end) of the year.
+func (info *iterInfo) fillNthWeekdays(first, end int) {
+	last := end - 1
+	for _, y := range info.rrule.bydays {
+		wday, n := y.weekday, y.n
+		var i int
+		if n < 0 {
+			i = last + (n+1)*7
+			i -= pymod(info.wdaymask[i]-wday, 7)
+		} else {
+			i = first + (n-1)*7
+			i += pymod(7-info.wdaymask[i]+wday, 7)
+		}
+		if first <= i && i <= last {
+			info.nwdaymask[i] = 1
+		}
+	}
+}
+
+// excludedDay reports whether the day i of the year is filtered out by the
+// BY* rule parts other than BYMONTH and BYMONTHDAY.
+func (info *iterInfo) excludedDay(i int) bool {
+	r := info.rrule
+	return len(r.byweekno) != 0 && info.wnomask[i] == 0 ||
+		len(r.byweekday) != 0 && !contains(r.byweekday, info.wdaymask[i]) ||
+		len(info.nwdaymask) != 0 && (i >= len(info.nwdaymask) || info.nwdaymask[i] == 0) ||
+		len(r.byeaster) != 0 && info.eastermask[i] == 0 ||
+		len(r.byyearday) != 0 &&
+			(i < info.yearlen &&
+				!contains(r.byyearday, i+1) &&
+				!contains(r.byyearday, -info.yearlen+i) ||
+				i >= info.yearlen &&
+					!contains(r.byyearday, i+1-info.yearlen) &&
+					!contains(r.byyearday, -info.nextyearlen+i-info.yearlen))
+}
+
 func (info *iterInfo) calcDaySet(freq Frequency, year int, month time.Month, day int) (start, end int) {
 	switch freq {
 	case Yearly:
@@ -419,7 +625,7 @@ func (info *iterInfo) calcDaySet(freq Frequency, year int, month time.Month, day
 
 	case Weekly:
 		// We need to handle cross-year weeks here.
-		i := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).YearDay() - 1
+		i := info.mrange[month-1] + day - 1
 		start, end = i, i+1
 		for j := 0; j < 7; j++ {
 			i++
@@ -437,7 +643,7 @@ func (info *iterInfo) calcDaySet(freq Frequency, year int, month time.Month, day
 
 	default:
 		// DAILY, HOURLY, MINUTELY, SECONDLY:
-		i := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).YearDay() - 1
+		i := info.mrange[month-1] + day - 1
 		return i, i + 1
 	}
 }
@@ -451,13 +657,11 @@ func (info *iterInfo) fillTimeSet(set *[]time.Time, freq Frequency, hour, minute
 				*set = append(*set, time.Date(1, 1, 1, hour, minute, second, 0, info.rrule.dtstart.Location()))
 			}
 		}
-		sort.Sort(timeSlice(*set))
 	case Minutely:
 		prepareTimeSet(set, len(info.rrule.bysecond))
 		for _, second := range info.rrule.bysecond {
 			*set = append(*set, time.Date(1, 1, 1, hour, minute, second, 0, info.rrule.dtstart.Location()))
 		}
-		sort.Sort(timeSlice(*set))
 	case Secondly:
 		prepareTimeSet(set, 1)
 		*set = append(*set, time.Date(1, 1, 1, hour, minute, second, 0, info.rrule.dtstart.Location()))
@@ -467,7 +671,7 @@ func (info *iterInfo) fillTimeSet(set *[]time.Time, freq Frequency, hour, minute
 }
 
 func prepareTimeSet(set *[]time.Time, length int) {
-	if len(*set) < length {
+	if cap(*set) < length {
 		*set = make([]time.Time, 0, length)
 		return
 	}
@@ -475,7 +679,9 @@ func prepareTimeSet(set *[]time.Time, length int) {
 	*set = (*set)[:0]
 }
 
-// rIterator is a iterator of RRule
+// rIterator is a iterator of RRule.
+// Once its buffers have grown to the largest period, it generates the
+// occurrences of a Gregorian rule without allocating.
 type rIterator struct {
 	year     int
 	month    time.Month
@@ -491,6 +697,10 @@ type rIterator struct {
 	remain   reusingRemainSlice
 	finished bool
 	dayset   []optInt
+	last     time.Time
+	// Buffers reused by every period.
+	days    []int
+	poslist []time.Time
 }
 
 func (iterator *rIterator) generate() {
@@ -510,29 +720,32 @@ func (iterator *rIterator) generate() {
 		// Do the "hard" work ;-)
 		for dayIndex, day := range dayset {
 			i := day.Int
-			if len(r.bymonth) != 0 && !contains(r.bymonth, iterator.ii.mmask[i]) ||
-				len(r.byweekno) != 0 && iterator.ii.wnomask[i] == 0 ||
-				len(r.byweekday) != 0 && !contains(r.byweekday, iterator.ii.wdaymask[i]) ||
-				len(iterator.ii.nwdaymask) != 0 && iterator.ii.nwdaymask[i] == 0 ||
-				len(r.byeaster) != 0 && iterator.ii.eastermask[i] == 0 ||
+			if len(r.bymonth) != 0 && !contains(iterator.ii.bymonth, iterator.ii.mmask[i]) ||
 				(len(r.bymonthday) != 0 || len(r.bynmonthday) != 0) &&
 					!contains(r.bymonthday, iterator.ii.mdaymask[i]) &&
 					!contains(r.bynmonthday, iterator.ii.nmdaymask[i]) ||
-				len(r.byyearday) != 0 &&
-					(i < iterator.ii.yearlen &&
-						!contains(r.byyearday, i+1) &&
-						!contains(r.byyearday, -iterator.ii.yearlen+i) ||
-						i >= iterator.ii.yearlen &&
-							!contains(r.byyearday, i+1-iterator.ii.yearlen) &&
-							!contains(r.byyearday, -iterator.ii.nextyearlen+i-iterator.ii.yearlen)) {
+				iterator.ii.excludedDay(i) {
 				dayset[dayIndex].Defined = false
 				filtered = true
 			}
 		}
+		if r.skip != Omit && (len(r.bymonthday) != 0 || len(r.bynmonthday) != 0) {
+			iterator.skipDaySet(setStart, setEnd)
+			dayset = iterator.dayset
+		}
+		if len(r.bybusinessday) != 0 {
+			iterator.selectBusinessDays()
+		}
 
 		// Output results
 		if len(r.bysetpos) != 0 && len(iterator.timeset) != 0 {
-			var poslist []time.Time
+			poslist := iterator.poslist[:0]
+			iterator.days = iterator.days[:0]
+			for _, day := range dayset {
+				if day.Defined {
+					iterator.days = append(iterator.days, day.Int)
+				}
+			}
 			for _, pos := range r.bysetpos {
 				var daypos, timepos int
 				if pos < 0 {
@@ -540,13 +753,7 @@ func (iterator *rIterator) generate() {
 				} else {
 					daypos, timepos = divmod(pos-1, len(iterator.timeset))
 				}
-				var temp []int
-				for _, day := range dayset {
-					if day.Defined {
-						temp = append(temp, day.Int)
-					}
-				}
-				i, err := pySubscript(temp, daypos)
+				i, err := pySubscript(iterator.days, daypos)
 				if err != nil {
 					continue
 				}
@@ -557,22 +764,20 @@ func (iterator *rIterator) generate() {
 					tempHour, tempMinute, tempSecond,
 					timeTemp.Nanosecond(), timeTemp.Location())
 				if !timeContains(poslist, res) {
-					poslist = append(poslist, res)
+					poslist = insertTime(poslist, res)
 				}
 			}
-			sort.Sort(timeSlice(poslist))
+			iterator.poslist = poslist
 			for _, res := range poslist {
 				if !r.until.IsZero() && res.After(r.until) {
-					r.len = iterator.total
 					iterator.finished = true
 					return
-				} else if !res.Before(r.dtstart) {
+				} else if !res.Before(r.dtstart) && iterator.unseen(res) {
 					iterator.total++
 					iterator.remain.Append(res)
 					if iterator.count != 0 {
 						iterator.count--
 						if iterator.count == 0 {
-							r.len = iterator.total
 							iterator.finished = true
 							return
 						}
@@ -592,16 +797,14 @@ func (iterator *rIterator) generate() {
 						tempHour, tempMinute, tempSecond,
 						timeTemp.Nanosecond(), timeTemp.Location())
 					if !r.until.IsZero() && res.After(r.until) {
-						r.len = iterator.total
 						iterator.finished = true
 						return
-					} else if !res.Before(r.dtstart) {
+					} else if !res.Before(r.dtstart) && iterator.unseen(res) {
 						iterator.total++
 						iterator.remain.Append(res)
 						if iterator.count != 0 {
 							iterator.count--
 							if iterator.count == 0 {
-								r.len = iterator.total
 								iterator.finished = true
 								return
 							}
@@ -615,11 +818,21 @@ func (iterator *rIterator) generate() {
 		if r.freq == Yearly {
 			iterator.year += r.interval
 			if iterator.year > MAXYEAR {
-				r.len = iterator.total
 				iterator.finished = true
 				return
 			}
 			iterator.ii.rebuild(iterator.year, iterator.month)
+		} else if r.freq == Monthly && r.calendar != nil {
+			iterator.month += time.Month(r.interval)
+			for monthsIn := iterator.ii.monthsIn(iterator.year); int(iterator.month) > monthsIn; monthsIn = iterator.ii.monthsIn(iterator.year) {
+				iterator.month -= time.Month(monthsIn)
+				iterator.year++
+				if iterator.year > MAXYEAR {
+					iterator.finished = true
+					return
+				}
+			}
+			iterator.ii.rebuild(iterator.year, iterator.month)
 		} else if r.freq == Monthly {
 			iterator.month += time.Month(r.interval)
 			if iterator.month > 12 {
@@ -631,7 +844,6 @@ func (iterator *rIterator) generate() {
 					iterator.year--
 				}
 				if iterator.year > MAXYEAR {
-					r.len = iterator.total
 					iterator.finished = true
 					return
 				}
@@ -722,21 +934,20 @@ func (iterator *rIterator) generate() {
 			iterator.ii.fillTimeSet(&iterator.timeset, r.freq, iterator.hour, iterator.minute, iterator.second)
 		}
 		if fixday && iterator.day > 28 {
-			daysinmonth := daysIn(iterator.month, iterator.year)
+			daysinmonth := iterator.ii.daysIn(iterator.month, iterator.year)
 			if iterator.day > daysinmonth {
 				for iterator.day > daysinmonth {
 					iterator.day -= daysinmonth
 					iterator.month++
-					if iterator.month == 13 {
+					if int(iterator.month) > iterator.ii.monthsIn(iterator.year) {
 						iterator.month = 1
 						iterator.year++
 						if iterator.year > MAXYEAR {
-							r.len = iterator.total
 							iterator.finished = true
 							return
 						}
 					}
-					daysinmonth = daysIn(iterator.month, iterator.year)
+					daysinmonth = iterator.ii.daysIn(iterator.month, iterator.year)
 				}
 				iterator.ii.rebuild(iterator.year, iterator.month)
 			}
@@ -744,6 +955,93 @@ func (iterator *rIterator) generate() {
 	}
 }
 
+// skipDaySet adds to the day set the dates that SKIP substitutes for the
+// BYMONTHDAY values exceeding the months of the period, RFC 7529 section 4.2.
+// FORWARD and BACKWARD may leave the period, the date is still generated with
+// it so that the source month decides, as BYMONTH and INTERVAL would.
+func (iterator *rIterator) skipDaySet(start, end int) {
+	r, ii := iterator.ii.rrule, &iterator.ii
+	added := false
+	for month := 1; month < len(ii.mrange); month++ {
+		first, last := ii.mrange[month-1], ii.mrange[month]-1
+		if len(r.bymonth) != 0 && !contains(ii.bymonth, ii.mmask[first]) {
+			continue
+		}
+		targets := iterator.days[:0]
+		for _, mday := range r.bymonthday {
+			if mday > last-first+1 && r.skip == Backward {
+				targets = append(targets, last)
+			} else if mday > last-first+1 {
+				targets = append(targets, last+1)
+			}
+		}
+		for _, mday := range r.bynmonthday {
+			if -mday > last-first+1 && r.skip == Backward {
+				targets = append(targets, first-1)
+			} else if -mday > last-first+1 {
+				targets = append(targets, first)
+			}
+		}
+		for _, i := range targets {
+			// Yearly and monthly periods generate the substitutes of their
+			// months, shorter periods the substitutes falling into them.
+			if r.freq <= Monthly && (last < start || first >= end) ||
+				r.freq > Monthly && (i < start || i >= end) ||
+				i < 0 || ii.excludedDay(i) {
+				continue
+			}
+			if start <= i && i < end {
+				iterator.dayset[i-start].Defined = true
+			} else if !optIntContains(iterator.dayset[end-start:], i) {
+				iterator.dayset = append(iterator.dayset, optInt{Int: i, Defined: true})
+				added = true
+			}
+		}
+		iterator.days = targets
+	}
+	if added {
+		sortOptInts(iterator.dayset)
+	}
+}
+
+// selectBusinessDays keeps in the day set the nth business days of
+// BYBUSINESSDAY among the days left by the other rule parts.
+func (iterator *rIterator) selectBusinessDays() {
+	r, ii := iterator.ii.rrule, &iterator.ii
+	days := iterator.days[:0]
+	for dayIndex, day := range iterator.dayset {
+		if !day.Defined {
+			continue
+		}
+		if r.businessCalendar.IsBusinessDay(ii.firstyday.AddDate(0, 0, day.Int)) {
+			days = append(days, dayIndex)
+		}
+		iterator.dayset[dayIndex].Defined = false
+	}
+	for _, pos := range r.bybusinessday {
+		if pos > 0 {
+			pos--
+		}
+		if dayIndex, err := pySubscript(days, pos); err == nil {
+			iterator.dayset[dayIndex].Defined = true
+		}
+	}
+	iterator.days = days
+}
+
+// unseen reports whether res is after every occurrence generated so far,
+// which SKIP may have generated with an earlier period.
+func (iterator *rIterator) unseen(res time.Time) bool {
+	if iterator.ii.rrule.skip == Omit {
+		return true
+	}
+	if !iterator.last.IsZero() && !res.After(iterator.last) {
+		return false
+	}
+	iterator.last = res
+	return true
+}
+
 func (iterator *rIterator) fillDaySetMonotonic(start, end int) {
 	desiredLen := end - start
 
@@ -798,10 +1096,43 @@ func (s *reusingRemainSlice) Pop() (ret time.Time, ok bool) {
 
 // Iterator return an iterator for RRule
 func (r *RRule) Iterator() Next {
-	iterator := rIterator{}
-	iterator.year, iterator.month, iterator.day = r.dtstart.Date()
-	iterator.hour, iterator.minute, iterator.second = r.dtstart.Clock()
-	iterator.weekday = toPyWeekday(r.dtstart.Weekday())
+	if r.cache != nil {
+		return r.cache.iterator(r.iterator)
+	}
+	return r.iterator()
+}
+
+// EnableCache memoizes the first size occurrences, so that repeated queries
+// are served from memory. Only this prefix is cached, a query past it
+// generates the later occurrences from DTSTART again. The cache is emptied
+// whenever the rule is modified, a size not above 0 disables it.
+func (r *RRule) EnableCache(size int) {
+	r.cache = newOccurrenceCache(size)
+}
+
+func (r *RRule) iterator() Next {
+	year, month, day := r.dtstart.Date()
+	if r.calendar != nil {
+		var cmonth int
+		year, cmonth, day = r.calendar.FromGregorian(r.dtstart.Date())
+		month = time.Month(cmonth)
+	}
+	hour, minute, second := r.dtstart.Clock()
+	return r.iteratorAt(year, month, day, hour, minute, second, toPyWeekday(r.dtstart.Weekday()))
+}
+
+// iteratorAt returns an iterator starting with the period of the given
+// date and time, which must be aligned with DTSTART and INTERVAL.
+func (r *RRule) iteratorAt(year int, month time.Month, day, hour, minute, second, weekday int) Next {
+	iterator := rIterator{
+		year:    year,
+		month:   month,
+		day:     day,
+		hour:    hour,
+		minute:  minute,
+		second:  second,
+		weekday: weekday,
+	}
 
 	iterator.ii = iterInfo{rrule: r}
 	iterator.ii.rebuild(iterator.year, iterator.month)
@@ -853,12 +1184,94 @@ func (r *RRule) After(dt time.Time, inc bool) time.Time {
 	return after(r.Iterator(), dt, inc)
 }
 
+// Contains reports whether dt is an occurrence of the RRule.
+// Only the period of dt is generated, unless COUNT, SKIP or RSCALE require
+// counting the occurrences from DTSTART.
+func (r *RRule) Contains(dt time.Time) bool {
+	if dt.Before(r.dtstart) || dt.After(r.until) {
+		return false
+	}
+	if r.count != 0 || r.skip != Omit || r.calendar != nil {
+		return after(r.Iterator(), dt, true).Equal(dt)
+	}
+	next, ok := r.periodIterator(dt.In(r.dtstart.Location()))
+	if !ok {
+		return false
+	}
+	for v, ok := next(); ok; v, ok = next() {
+		if !v.Before(dt) {
+			return v.Equal(dt)
+		}
+	}
+	return false
+}
+
+// CountBetween returns the number of occurrences Between would return
+// without generating them, whole periods are counted at once when every
+// period has the same number of occurrences.
+func (r *RRule) CountBetween(after, before time.Time, inc bool) int {
+	return countBetween(r.countBefore, r.Contains, after, before, inc)
+}
+
+// Index returns the 0-based index of the occurrence dt, ok is false when dt
+// is not an occurrence.
+func (r *RRule) Index(dt time.Time) (index int, ok bool) {
+	if !r.Contains(dt) {
+		return -1, false
+	}
+	return r.countBefore(dt), true
+}
+
+// Nth returns the 0-based nth occurrence, ok is false when there is none.
+// Whole periods are skipped when every period has the same number of
+// occurrences.
+func (r *RRule) Nth(n int) (time.Time, bool) {
+	return r.nth(n)
+}
+
+// IsFinite reports whether the RRule has a COUNT or an UNTIL.
+func (r *RRule) IsFinite() bool {
+	return r.count != 0 || !r.Options.Until.IsZero()
+}
+
+// Last returns the last occurrence of the RRule, the zero time when there is
+// none. It returns ErrInfinite when the RRule is not finite.
+func (r *RRule) Last() (time.Time, error) {
+	if !r.IsFinite() {
+		return time.Time{}, ErrInfinite
+	}
+	n := r.countBefore(r.until.Add(time.Second))
+	if n == 0 {
+		return time.Time{}, nil
+	}
+	last, _ := r.nth(n - 1)
+	return last, nil
+}
+
 // DTStart set a new DTSTART for the rule and recalculates the timeset if needed.
 // It will be truncated to second precision.
-// Default to `time.Now().UTC().Truncate(time.Second)`.
+// Default to `Clock.Now().UTC().Truncate(time.Second)`.
 func (r *RRule) DTStart(dt time.Time) {
 	r.OrigOptions.Dtstart = dt.Truncate(time.Second)
+	r.rebuild()
+}
+
+// rebuild builds the rule again from its OrigOptions after a modification,
+// emptying its cache and stamping a new version.
+func (r *RRule) rebuild() {
+	cache, version := r.cache.renew(), r.version+1
 	*r = buildRRule(r.OrigOptions)
+	r.cache, r.version = cache, version
+}
+
+// WithDTStart returns a copy of the rule with a new DTSTART, leaving the
+// rule unchanged. It will be truncated to second precision.
+func (r *RRule) WithDTStart(dt time.Time) *RRule {
+	option := r.OrigOptions
+	option.Dtstart = dt.Truncate(time.Second)
+	rule := buildRRule(option)
+	rule.cache = r.cache.renew()
+	return &rule
 }
 
 // GetDTStart gets DTSTART time for rrule
@@ -871,7 +1284,24 @@ func (r *RRule) GetDTStart() time.Time {
 // Default to `Dtstart.Add(time.Duration(1<<63 - 1))`, approximately 290 years.
 func (r *RRule) Until(ut time.Time) {
 	r.OrigOptions.Until = ut.Truncate(time.Second)
-	*r = buildRRule(r.OrigOptions)
+	r.rebuild()
+}
+
+// BusinessCalendar sets the calendar defining the business days of
+// Bybusinessday.
+func (r *RRule) BusinessCalendar(calendar *Calendar) {
+	r.OrigOptions.BusinessCalendar = calendar
+	r.rebuild()
+}
+
+// WithUntil returns a copy of the rule with a new UNTIL, leaving the rule
+// unchanged. It will be truncated to second precision.
+func (r *RRule) WithUntil(ut time.Time) *RRule {
+	option := r.OrigOptions
+	option.Until = ut.Truncate(time.Second)
+	rule := buildRRule(option)
+	rule.cache = r.cache.renew()
+	return &rule
 }
 
 // GetUntil gets UNTIL time for rrule
@@ -1,6 +1,7 @@
 package rrule
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -37,3 +38,13 @@ func toWeekdayString(v time.Weekday) string {
 		return "UNKNOWN"
 	}
 }
+
+func parseWeekdayString(s string) (time.Weekday, error) {
+	for wday := time.Sunday; wday <= time.Saturday; wday++ {
+		if toWeekdayString(wday) == s {
+			return wday, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s", ErrInvalidValue, s)
+}
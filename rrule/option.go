@@ -75,3 +75,9 @@ func WeekStart(v time.Weekday) func(r *RRule) {
 		r.WeekStart = v
 	}
 }
+
+func ByEaster(v ...int) func(r *RRule) {
+	return func(r *RRule) {
+		r.ByEaster = v
+	}
+}
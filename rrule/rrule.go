@@ -1,7 +1,9 @@
 package rrule
 
 import (
+	"database/sql/driver"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -24,6 +26,9 @@ type RRule struct {
 	ByMonth    []int          `json:"bymonth,omitempty"`
 	BySetpos   []int          `json:"bysetpos,omitempty"`
 	WeekStart  time.Weekday   `json:"wkst,omitempty"` // default: Monday
+	// ByEaster is not part of RFC 5545, it is the day offsets from Easter
+	// Sunday, e.g. -2 for Good Friday.
+	ByEaster []int `json:"byeaster,omitempty"`
 }
 
 func New(freq Frequency, interval int, opts ...Option) (*RRule, error) {
@@ -79,6 +84,7 @@ func (t *RRule) Validate() error {
 		{t.ByWeekNo, "byweekno", []int{1, 53}, true},
 		{t.ByMonth, "bymonth", []int{1, 12}, false},
 		{t.BySetpos, "bysetpos", []int{1, 366}, true},
+		{t.ByEaster, "byeaster", []int{0, 366}, true},
 	} {
 		for _, value := range b.field {
 			if err := checkBounds(b.param, value, b.bound, b.plusMinus); err != nil {
@@ -125,18 +131,112 @@ func (t *RRule) String() string {
 	result = appendOption(result, "BYWEEKNO", t.ByWeekNo)
 	result = appendOption(result, "BYMONTH", t.ByMonth)
 	result = appendOption(result, "BYSETPOS", t.BySetpos)
+	result = appendOption(result, "BYEASTER", t.ByEaster)
 
 	return strings.Join(result, ";")
 }
 
 // The interface of sql.scanner.
 func (t *RRule) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("%w: %T", ErrInvalidValue, src)
+	}
+	values, err := splitComposite(s)
+	if err != nil {
+		return err
+	}
+	// byeaster is absent before the extension migration.
+	if len(values) != 14 && len(values) != 15 {
+		return fmt.Errorf("%w: %s", ErrInvalidValue, s)
+	}
+
+	r := RRule{WeekStart: time.Monday}
+	if r.Frequency, err = NewFrequencyFromString(values[0]); err != nil {
+		return err
+	}
+	if r.Interval, err = parseInt(values[1]); err != nil {
+		return err
+	}
+	if r.Count, err = parseInt(values[2]); err != nil {
+		return err
+	}
+	if values[3] != "" {
+		if r.Until, err = time.Parse(time.DateTime, values[3]); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidValue, values[3])
+		}
+	}
+	for i, field := range []*[]int{
+		&r.BySecond, &r.ByMinute, &r.ByHour, nil, &r.ByMonthDay,
+		&r.ByYearDay, &r.ByWeekNo, &r.ByMonth, &r.BySetpos,
+	} {
+		if field == nil {
+			continue
+		}
+		if *field, err = parseIntArray(values[4+i]); err != nil {
+			return err
+		}
+	}
+	if values[7] != "" {
+		for _, v := range strings.Split(strings.Trim(values[7], "{}"), ",") {
+			wday, err := parseWeekdayString(v)
+			if err != nil {
+				return err
+			}
+			r.ByDay = append(r.ByDay, wday)
+		}
+	}
+	if values[13] != "" {
+		if r.WeekStart, err = parseWeekdayString(values[13]); err != nil {
+			return err
+		}
+	}
+	if len(values) == 15 {
+		if r.ByEaster, err = parseIntArray(values[14]); err != nil {
+			return err
+		}
+	}
+	if err = r.Validate(); err != nil {
+		return err
+	}
+	*t = r
+
 	return nil
 }
 
 // The interface of sql.valuer.
-func (t *RRule) Value() (interface{}, error) {
-	s := []string{}
-	s = append(s, t.Frequency.String())
+func (t *RRule) Value() (driver.Value, error) {
+	s := []string{t.Frequency.String()}
+	s = append(s, strconv.Itoa(t.Interval))
+	if t.Count != 0 {
+		s = append(s, strconv.Itoa(t.Count))
+	} else {
+		s = append(s, "")
+	}
+	if !t.Until.IsZero() {
+		s = append(s, fmt.Sprintf("\"%s\"", t.Until.Format(time.DateTime)))
+	} else {
+		s = append(s, "")
+	}
+	s = append(s, toIntArray(t.BySecond), toIntArray(t.ByMinute), toIntArray(t.ByHour))
+	if len(t.ByDay) != 0 {
+		slice := make([]string, len(t.ByDay))
+		for i, wday := range t.ByDay {
+			slice[i] = toWeekdayString(wday)
+		}
+		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(slice, ",")))
+	} else {
+		s = append(s, "")
+	}
+	s = append(s, toIntArray(t.ByMonthDay), toIntArray(t.ByYearDay), toIntArray(t.ByWeekNo),
+		toIntArray(t.ByMonth), toIntArray(t.BySetpos), toWeekdayString(t.WeekStart))
+	// byeaster is an extension field added by migrations/20230702_rrule_byeaster.up.sql.
+	s = append(s, toIntArray(t.ByEaster))
 
+	return fmt.Sprintf("(%s)", strings.Join(s, ",")), nil
 }
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/kiraxie/rrule-go/internal/composite"
 )
 
 func repeat(value, count int) []int {
@@ -62,3 +64,40 @@ func checkBounds(param string, value int, bounds []int, plusMinus bool) error {
 
 	return nil
 }
+
+func toIntArray(v []int) string {
+	if len(v) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("\"{%s}\"", strings.Join(toStringSlice(v), ","))
+}
+
+func parseInt(s string) (int, error) {
+	v, err := composite.ParseInt(s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidValue, err)
+	}
+
+	return v, nil
+}
+
+func parseIntArray(s string) ([]int, error) {
+	v, err := composite.ParseIntArray(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidValue, err)
+	}
+
+	return v, nil
+}
+
+// splitComposite splits the text representation of a PostgreSQL composite
+// value into its unquoted fields.
+func splitComposite(s string) ([]string, error) {
+	fields, err := composite.Split(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidValue, err)
+	}
+
+	return fields, nil
+}
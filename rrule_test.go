@@ -4985,3 +4985,263 @@ func iterateNum(iter Next, num int) (last time.Time) {
 	}
 	return last
 }
+
+func TestContains(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2023, 12, 29, 9, 30, 0, 0, time.UTC)
+	until := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, option := range []ROption{
+		{Freq: Yearly, Interval: 2, Bymonth: []int{1, 6}, Byweekday: []Weekday{Monday}},
+		{Freq: Yearly, Byyearday: []int{1, -1}, Byhour: []int{9, 21}},
+		{Freq: Monthly, Interval: 3, Byweekday: []Weekday{Friday.Nth(-1)}},
+		{Freq: Monthly, Bymonthday: []int{31}, Bysetpos: []int{1}},
+		{Freq: Weekly, Interval: 2, Byweekday: []Weekday{Monday, Thursday}, Wkst: Sunday},
+		{Freq: Weekly, Interval: 3},
+		{Freq: Daily, Interval: 5, Byhour: []int{9, 18}, Byminute: []int{0, 30}},
+		{Freq: Hourly, Interval: 7, Byweekday: []Weekday{Saturday}},
+		{Freq: Minutely, Interval: 90, Bymonthday: []int{1}},
+		{Freq: Secondly, Interval: 3600 * 5, Bymonth: []int{2}},
+	} {
+		option.Dtstart, option.Until = dtstart, until
+		r, err := NewRRule(option)
+		assert.NoError(t, err)
+		occurrences := map[time.Time]bool{}
+		for _, dt := range r.All() {
+			occurrences[dt] = true
+			assert.True(t, r.Contains(dt), "%s %s", option.RRuleString(), dt)
+		}
+		for dt := dtstart.Add(-time.Hour); dt.Before(until); dt = dt.Add(30 * time.Minute) {
+			assert.Equal(t, occurrences[dt], r.Contains(dt), "%s %s", option.RRuleString(), dt)
+		}
+	}
+
+	r, _ := NewRRule(ROption{Freq: Daily, Count: 3, Dtstart: dtstart})
+	assert.True(t, r.Contains(dtstart.AddDate(0, 0, 2)))
+	assert.False(t, r.Contains(dtstart.AddDate(0, 0, 3)))
+	assert.False(t, r.Contains(dtstart.Add(time.Second)))
+
+	set := &Set{}
+	set.RRule(r)
+	set.RDate(dtstart.AddDate(0, 0, 10))
+	set.ExDate(dtstart.AddDate(0, 0, 1))
+	assert.True(t, set.Contains(dtstart))
+	assert.False(t, set.Contains(dtstart.AddDate(0, 0, 1)))
+	assert.True(t, set.Contains(dtstart.AddDate(0, 0, 10)))
+}
+
+func BenchmarkContains(b *testing.B) {
+	dtstart := time.Date(2000, 0o3, 22, 12, 0, 0, 0, time.UTC)
+	for _, freq := range []Frequency{Yearly, Monthly, Weekly, Daily, Hourly} {
+		rrule, err := NewRRule(ROption{Dtstart: dtstart, Freq: freq})
+		if err != nil {
+			b.Errorf("failed to init rrule: %s", err)
+		}
+		dt := rrule.After(time.Date(2030, 0o3, 22, 12, 0, 0, 0, time.UTC), true)
+		b.Run(freq.String(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if !rrule.Contains(dt) {
+					b.Error("expected an occurrence")
+				}
+			}
+		})
+		b.Run(freq.String()+" by iteration", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if !rrule.After(dt, true).Equal(dt) {
+					b.Error("expected an occurrence")
+				}
+			}
+		})
+	}
+}
+
+func TestNthAndIndex(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2023, 12, 29, 9, 30, 0, 0, time.UTC)
+	until := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, option := range []ROption{
+		{Freq: Yearly, Bymonth: []int{1, 6, 12}, Bymonthday: []int{5, 28}},
+		{Freq: Yearly, Interval: 2, Byweekday: []Weekday{Monday}},
+		{Freq: Monthly, Interval: 5, Bymonthday: []int{1, 15}, Byhour: []int{8, 20}},
+		{Freq: Monthly, Bymonthday: []int{31}},
+		{Freq: Weekly, Interval: 2, Byweekday: []Weekday{Monday, Thursday, Saturday}, Wkst: Sunday},
+		{Freq: Daily, Interval: 3, Byhour: []int{9, 18}, Byminute: []int{0, 30}},
+		{Freq: Daily, Count: 40},
+		{Freq: Hourly, Interval: 17},
+		{Freq: Hourly, Interval: 5, Byhour: []int{1, 2, 3}},
+		{Freq: Minutely, Interval: 997, Bysecond: []int{0, 30}},
+		{Freq: Secondly, Interval: 86399},
+	} {
+		option.Dtstart = dtstart
+		if option.Count == 0 {
+			option.Until = until
+		}
+		r, err := NewRRule(option)
+		assert.NoError(t, err)
+		occurrences := r.All()
+		for i, dt := range occurrences {
+			nth, ok := r.Nth(i)
+			assert.True(t, ok)
+			assert.Equal(t, dt, nth, "%s %d", option.RRuleString(), i)
+			index, ok := r.Index(dt)
+			assert.True(t, ok)
+			assert.Equal(t, i, index, "%s %s", option.RRuleString(), dt)
+		}
+		_, ok := r.Nth(len(occurrences))
+		assert.False(t, ok, option.RRuleString())
+		_, ok = r.Index(dtstart.Add(time.Second))
+		assert.False(t, ok)
+	}
+
+	r, _ := NewRRule(ROption{Freq: Daily, Dtstart: dtstart})
+	nth, ok := r.Nth(500)
+	assert.True(t, ok)
+	assert.Equal(t, dtstart.AddDate(0, 0, 500), nth)
+
+	set := &Set{}
+	set.RRule(r)
+	set.RDate(dtstart.Add(time.Hour))
+	set.RDate(dtstart.AddDate(0, 0, 1))
+	set.ExDate(dtstart.AddDate(0, 0, 2))
+	index, ok := set.Index(dtstart.AddDate(0, 0, 3))
+	assert.True(t, ok)
+	assert.Equal(t, 3, index)
+	nth, ok = set.Nth(3)
+	assert.True(t, ok)
+	assert.Equal(t, dtstart.AddDate(0, 0, 3), nth)
+}
+
+func BenchmarkNth(b *testing.B) {
+	dtstart := time.Date(2000, 0o3, 22, 12, 0, 0, 0, time.UTC)
+	rrule, err := NewRRule(ROption{Dtstart: dtstart, Freq: Daily, Byhour: []int{9, 12, 18}})
+	if err != nil {
+		b.Errorf("failed to init rrule: %s", err)
+	}
+	for i := 0; i < b.N; i++ {
+		if _, ok := rrule.Nth(10000); !ok {
+			b.Error("expected an occurrence")
+		}
+	}
+}
+
+func TestCountBetween(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2023, 12, 29, 9, 30, 0, 0, time.UTC)
+	for _, option := range []ROption{
+		{Freq: Yearly, Bymonth: []int{1, 6, 12}, Bymonthday: []int{5, 28}},
+		{Freq: Monthly, Interval: 2, Byweekday: []Weekday{Friday.Nth(-1)}},
+		{Freq: Weekly, Byweekday: []Weekday{Monday, Thursday}, Count: 50},
+		{Freq: Daily, Byhour: []int{9, 18}},
+		{Freq: Hourly, Interval: 7},
+	} {
+		option.Dtstart = dtstart
+		r, err := NewRRule(option)
+		assert.NoError(t, err)
+		bounds := []time.Time{
+			dtstart.Add(-time.Hour), dtstart, dtstart.AddDate(0, 1, 0),
+			r.After(dtstart.AddDate(0, 3, 0), false), dtstart.AddDate(1, 0, 0),
+		}
+		for _, after := range bounds {
+			for _, before := range bounds {
+				for _, inc := range []bool{true, false} {
+					assert.Equal(t, len(r.Between(after, before, inc)), r.CountBetween(after, before, inc),
+						"%s %s %s %v", option.RRuleString(), after, before, inc)
+				}
+			}
+		}
+	}
+
+	r, _ := NewRRule(ROption{Freq: Daily, Dtstart: dtstart})
+	set := &Set{}
+	set.RRule(r)
+	set.RDate(dtstart.Add(time.Hour))
+	set.ExDate(dtstart.AddDate(0, 0, 2))
+	assert.Equal(t, 2, set.CountBetween(dtstart, dtstart.AddDate(0, 0, 3), false))
+	assert.Equal(t, 4, set.CountBetween(dtstart, dtstart.AddDate(0, 0, 3), true))
+}
+
+func BenchmarkCountBetween(b *testing.B) {
+	dtstart := time.Date(2000, 0o3, 22, 12, 0, 0, 0, time.UTC)
+	for _, option := range []ROption{
+		{Dtstart: dtstart, Freq: Hourly},
+		{Dtstart: dtstart, Freq: Daily, Byweekday: []Weekday{Monday, Friday}},
+	} {
+		rrule, err := NewRRule(option)
+		if err != nil {
+			b.Errorf("failed to init rrule: %s", err)
+		}
+		after, before := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		b.Run(option.RRuleString(), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if rrule.CountBetween(after, before, true) == 0 {
+					b.Error("expected occurrences")
+				}
+			}
+		})
+	}
+}
+
+// iteratorAllocsCases are rules of every frequency whose steady-state
+// iteration must not allocate.
+func iteratorAllocsCases() map[string]ROption {
+	dtstart := time.Date(2000, 0o3, 22, 12, 0, 0, 0, time.UTC)
+	return map[string]ROption{
+		"yearly":                {Freq: Yearly, Dtstart: dtstart, Bymonth: []int{1, 6}, Byweekday: []Weekday{Monday.Nth(1)}},
+		"yearly byweekno":       {Freq: Yearly, Dtstart: dtstart, Byweekno: []int{1, 20}},
+		"yearly byeaster":       {Freq: Yearly, Dtstart: dtstart, Byeaster: []int{-2, 0}},
+		"monthly":               {Freq: Monthly, Dtstart: dtstart, Byweekday: []Weekday{Friday.Nth(-1)}},
+		"monthly bysetpos":      {Freq: Monthly, Dtstart: dtstart, Byweekday: []Weekday{Monday, Friday}, Bysetpos: []int{1, -1}},
+		"monthly skip":          {Freq: Monthly, Dtstart: dtstart, Bymonthday: []int{31}, Skip: Backward},
+		"monthly bybusinessday": {Freq: Monthly, Dtstart: dtstart, Bybusinessday: []int{1, -1}},
+		"weekly":                {Freq: Weekly, Dtstart: dtstart, Byweekday: []Weekday{Monday, Wednesday}},
+		"daily":                 {Freq: Daily, Dtstart: dtstart},
+		"hourly":                {Freq: Hourly, Dtstart: dtstart, Byminute: []int{30, 0}},
+		"minutely":              {Freq: Minutely, Dtstart: dtstart, Bysecond: []int{30, 0}},
+		"secondly":              {Freq: Secondly, Dtstart: dtstart, Byhour: []int{9, 17}},
+	}
+}
+
+// TestIteratorAllocs is not parallel, AllocsPerRun counts the allocations of
+// every goroutine.
+func TestIteratorAllocs(t *testing.T) {
+	for name, option := range iteratorAllocsCases() {
+		r, err := NewRRule(option)
+		if err != nil {
+			t.Fatalf("failed to init rrule: %s", err)
+		}
+		next := r.Iterator()
+		// Let the buffers grow to the size of the largest period.
+		for i := 0; i < 100; i++ {
+			next()
+		}
+		allocs := testing.AllocsPerRun(10, func() {
+			for i := 0; i < 100; i++ {
+				next()
+			}
+		})
+		assert.Zero(t, allocs, name)
+	}
+}
+
+func BenchmarkIteratorAllocs(b *testing.B) {
+	for name, option := range iteratorAllocsCases() {
+		r, err := NewRRule(option)
+		if err != nil {
+			b.Fatalf("failed to init rrule: %s", err)
+		}
+		b.Run(name, func(b *testing.B) {
+			next := r.Iterator()
+			for i := 0; i < 100; i++ {
+				next()
+			}
+			allocs := testing.AllocsPerRun(100, func() { next() })
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, ok := next(); !ok {
+					next = r.Iterator()
+				}
+			}
+			b.ReportMetric(allocs, "allocs/occurrence")
+		})
+	}
+}
@@ -3,17 +3,23 @@
 package rrule
 
 import (
+	"container/heap"
 	"fmt"
 	"sort"
 	"time"
 )
 
 // Set allows more complex recurrence setups, mixing multiple rules, dates, exclusion rules, and exclusion dates
+//
+// A Set is safe for concurrent use by multiple goroutines as long as none of
+// them modifies it with DTStart, RRule, RDate, ExDate or their variants.
+// The RRule of a Set is never modified, a copy is made to change its DTSTART.
 type Set struct {
 	dtstart time.Time
 	rrule   *RRule
 	rdate   []time.Time
 	exdate  []time.Time
+	cache   *occurrenceCache
 }
 
 // Recurrence returns a slice of all the recurrence rules for a set
@@ -26,7 +32,7 @@ func (set *Set) Recurrence() []string {
 	}
 
 	if set.rrule != nil {
-		res = append(res, fmt.Sprintf("RRULE:%s", set.rrule.OrigOptions.RRuleString()))
+		res = append(res, fmt.Sprintf("%s:%s", set.rrule.OrigOptions.propertyName(), set.rrule.OrigOptions.RRuleString()))
 	}
 
 	for _, item := range set.rdate {
@@ -43,9 +49,10 @@ func (set *Set) Recurrence() []string {
 // It will be truncated to second precision.
 func (set *Set) DTStart(dtstart time.Time) {
 	set.dtstart = dtstart.Truncate(time.Second)
+	set.cache = set.cache.renew()
 
 	if set.rrule != nil {
-		set.rrule.DTStart(set.dtstart)
+		set.rrule = set.rrule.WithDTStart(set.dtstart)
 	}
 }
 
@@ -60,9 +67,10 @@ func (set *Set) RRule(rrule *RRule) {
 	if !rrule.OrigOptions.Dtstart.IsZero() {
 		set.dtstart = rrule.dtstart
 	} else if !set.dtstart.IsZero() {
-		rrule.DTStart(set.dtstart)
+		rrule = rrule.WithDTStart(set.dtstart)
 	}
 	set.rrule = rrule
+	set.cache = set.cache.renew()
 }
 
 // GetRRule returns the rrules in the set
@@ -73,7 +81,8 @@ func (set *Set) GetRRule() *RRule {
 // RDate include the given datetime instance in the recurrence set generation.
 // It will be truncated to second precision.
 func (set *Set) RDate(rdate time.Time) {
-	set.rdate = append(set.rdate, rdate.Truncate(time.Second))
+	set.rdate = insertTime(set.rdate, rdate.Truncate(time.Second))
+	set.cache = set.cache.renew()
 }
 
 // SetRDates sets explicitly added dates (rdates) in the set.
@@ -83,6 +92,8 @@ func (set *Set) SetRDates(rdates []time.Time) {
 	for _, rdate := range rdates {
 		set.rdate = append(set.rdate, rdate.Truncate(time.Second))
 	}
+	sort.Stable(timeSlice(set.rdate))
+	set.cache = set.cache.renew()
 }
 
 // GetRDate returns explicitly added dates (rdates) in the set
@@ -95,7 +106,8 @@ func (set *Set) GetRDate() []time.Time {
 // even if some inclusive rrule or rdate matches them.
 // It will be truncated to second precision.
 func (set *Set) ExDate(exdate time.Time) {
-	set.exdate = append(set.exdate, exdate.Truncate(time.Second))
+	set.exdate = insertTime(set.exdate, exdate.Truncate(time.Second))
+	set.cache = set.cache.renew()
 }
 
 // SetExDates sets explicitly excluded dates (exdates) in the set.
@@ -105,6 +117,18 @@ func (set *Set) SetExDates(exdates []time.Time) {
 	for _, exdate := range exdates {
 		set.exdate = append(set.exdate, exdate.Truncate(time.Second))
 	}
+	sort.Stable(timeSlice(set.exdate))
+	set.cache = set.cache.renew()
+}
+
+// insertTime inserts t into the sorted list after the times not after it,
+// so that iterating a Set never sorts shared slices.
+func insertTime(list []time.Time, t time.Time) []time.Time {
+	i := sort.Search(len(list), func(i int) bool { return list[i].After(t) })
+	list = append(list, time.Time{})
+	copy(list[i+1:], list[i:])
+	list[i] = t
+	return list
 }
 
 // GetExDate returns explicitly excluded dates (exdates) in the set
@@ -117,55 +141,80 @@ type genItem struct {
 	gen Next
 }
 
-type genItemSlice []genItem
+// genHeap is a min-heap of iterators by their next occurrence, merging k
+// iterators costs O(log k) per occurrence.
+type genHeap []genItem
 
-func (s genItemSlice) Len() int           { return len(s) }
-func (s genItemSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
-func (s genItemSlice) Less(i, j int) bool { return s[i].dt.Before(s[j].dt) }
+func (h genHeap) Len() int            { return len(h) }
+func (h genHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h genHeap) Less(i, j int) bool  { return h[i].dt.Before(h[j].dt) }
+func (h *genHeap) Push(x interface{}) { *h = append(*h, x.(genItem)) }
+func (h *genHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
 
-func addGenList(genList *[]genItem, next Next) {
+func addGenList(genList *genHeap, next Next) {
 	dt, ok := next()
 	if ok {
-		*genList = append(*genList, genItem{dt, next})
+		heap.Push(genList, genItem{dt, next})
+	}
+}
+
+// advance moves the iterator with the earliest occurrence to its next one.
+func (h *genHeap) advance() {
+	var ok bool
+	if (*h)[0].dt, ok = (*h)[0].gen(); ok {
+		heap.Fix(h, 0)
+	} else {
+		heap.Pop(h)
 	}
 }
 
 // Iterator returns an iterator for rrule.Set
-func (set *Set) Iterator() (next func() (time.Time, bool)) {
-	rlist := []genItem{}
-	exlist := []genItem{}
+func (set *Set) Iterator() Next {
+	if set.cache != nil {
+		if set.rrule != nil {
+			set.cache.validate(set.rrule.version)
+		}
+		return set.cache.iterator(set.iterator)
+	}
+	return set.iterator()
+}
+
+// EnableCache memoizes the first size occurrences, so that repeated queries
+// are served from memory. Only this prefix is cached, a query past it
+// generates the later occurrences from DTSTART again. The cache is emptied by
+// DTStart, RRule, RDate, ExDate and their variants, and by the modifications
+// of the RRule of GetRRule. A size not above 0 disables it.
+func (set *Set) EnableCache(size int) {
+	set.cache = newOccurrenceCache(size)
+}
 
-	sort.Sort(timeSlice(set.rdate))
-	addGenList(&rlist, timeSliceIterator(set.rdate))
+func (set *Set) iterator() Next {
+	rlist := &genHeap{}
+	exlist := &genHeap{}
+
+	addGenList(rlist, timeSliceIterator(set.rdate))
 	if set.rrule != nil {
-		addGenList(&rlist, set.rrule.Iterator())
+		addGenList(rlist, set.rrule.Iterator())
 	}
-	sort.Sort(genItemSlice(rlist))
 
-	sort.Sort(timeSlice(set.exdate))
-	addGenList(&exlist, timeSliceIterator(set.exdate))
-	sort.Sort(genItemSlice(exlist))
+	addGenList(exlist, timeSliceIterator(set.exdate))
 
 	lastdt := time.Time{}
 	return func() (time.Time, bool) {
-		for len(rlist) != 0 {
-			dt := rlist[0].dt
-			var ok bool
-			rlist[0].dt, ok = rlist[0].gen()
-			if !ok {
-				rlist = rlist[1:]
-			}
-			sort.Sort(genItemSlice(rlist))
+		for rlist.Len() != 0 {
+			dt := (*rlist)[0].dt
+			rlist.advance()
 			if lastdt.IsZero() || !lastdt.Equal(dt) {
-				for len(exlist) != 0 && exlist[0].dt.Before(dt) {
-					exlist[0].dt, ok = exlist[0].gen()
-					if !ok {
-						exlist = exlist[1:]
-					}
-					sort.Sort(genItemSlice(exlist))
+				for exlist.Len() != 0 && (*exlist)[0].dt.Before(dt) {
+					exlist.advance()
 				}
 				lastdt = dt
-				if len(exlist) == 0 || !dt.Equal(exlist[0].dt) {
+				if exlist.Len() == 0 || !dt.Equal((*exlist)[0].dt) {
 					return dt, true
 				}
 			}
@@ -205,3 +254,88 @@ func (set *Set) Before(dt time.Time, inc bool) time.Time {
 func (set *Set) After(dt time.Time, inc bool) time.Time {
 	return after(set.Iterator(), dt, inc)
 }
+
+// Contains reports whether dt is an occurrence of the rrule.Set.
+func (set *Set) Contains(dt time.Time) bool {
+	if timeContains(set.exdate, dt) {
+		return false
+	}
+	return timeContains(set.rdate, dt) || set.rrule != nil && set.rrule.Contains(dt)
+}
+
+// IsFinite reports whether the rrule.Set has no RRULE or a finite one.
+func (set *Set) IsFinite() bool {
+	return set.rrule == nil || set.rrule.IsFinite()
+}
+
+// Last returns the last occurrence of the rrule.Set, the zero time when there
+// is none. It returns ErrInfinite when the rrule.Set is not finite.
+func (set *Set) Last() (time.Time, error) {
+	if !set.IsFinite() {
+		return time.Time{}, ErrInfinite
+	}
+	var last time.Time
+	next := set.Iterator()
+	for v, ok := next(); ok; v, ok = next() {
+		last = v
+	}
+	return last, nil
+}
+
+// CountBetween returns the number of occurrences Between would return
+// without generating them.
+func (set *Set) CountBetween(after, before time.Time, inc bool) int {
+	return countBetween(set.countBefore, set.Contains, after, before, inc)
+}
+
+// Index returns the 0-based index of the occurrence dt, ok is false when dt
+// is not an occurrence.
+func (set *Set) Index(dt time.Time) (index int, ok bool) {
+	if !set.Contains(dt) {
+		return -1, false
+	}
+	return set.countBefore(dt), true
+}
+
+// Nth returns the 0-based nth occurrence, ok is false when there is none.
+func (set *Set) Nth(n int) (time.Time, bool) {
+	if len(set.rdate) == 0 && len(set.exdate) == 0 && set.rrule != nil {
+		return set.rrule.nth(n)
+	}
+	if n < 0 {
+		return time.Time{}, false
+	}
+	next := set.Iterator()
+	for v, ok := next(); ok; v, ok = next() {
+		if n == 0 {
+			return v, true
+		}
+		n--
+	}
+	return time.Time{}, false
+}
+
+// countBefore returns the number of occurrences before dt.
+func (set *Set) countBefore(dt time.Time) int {
+	n := 0
+	if set.rrule != nil {
+		n = set.rrule.countBefore(dt)
+	}
+	var seen []time.Time
+	for _, rdate := range set.rdate {
+		if rdate.Before(dt) && !timeContains(seen, rdate) &&
+			(set.rrule == nil || !set.rrule.Contains(rdate)) {
+			seen = append(seen, rdate)
+			n++
+		}
+	}
+	seen = seen[:0]
+	for _, exdate := range set.exdate {
+		if exdate.Before(dt) && !timeContains(seen, exdate) &&
+			(timeContains(set.rdate, exdate) || set.rrule != nil && set.rrule.Contains(exdate)) {
+			seen = append(seen, exdate)
+			n--
+		}
+	}
+	return n
+}
@@ -3,8 +3,11 @@
 package rrule
 
 import (
+	"fmt"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestSet(t *testing.T) {
@@ -401,3 +404,68 @@ func TestRuleSetChangeDTStartTimezoneRespected(t *testing.T) {
 		}
 	}
 }
+
+func TestSetLast(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	infinite, _ := NewRRule(ROption{Freq: Daily, Dtstart: dtstart})
+	assert.False(t, infinite.IsFinite())
+	_, err := infinite.Last()
+	assert.ErrorIs(t, err, ErrInfinite)
+
+	counted, _ := NewRRule(ROption{Freq: Weekly, Count: 1000, Dtstart: dtstart})
+	assert.True(t, counted.IsFinite())
+	last, err := counted.Last()
+	assert.NoError(t, err)
+	assert.Equal(t, dtstart.AddDate(0, 0, 7*999), last)
+
+	until, _ := NewRRule(ROption{Freq: Monthly, Bymonthday: []int{31}, Dtstart: dtstart,
+		Until: time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC)})
+	last, err = until.Last()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 10, 31, 9, 0, 0, 0, time.UTC), last)
+
+	empty, _ := NewRRule(ROption{Freq: Yearly, Bymonth: []int{2}, Bymonthday: []int{30}, Count: 2, Dtstart: dtstart})
+	last, err = empty.Last()
+	assert.NoError(t, err)
+	assert.True(t, last.IsZero())
+
+	set := &Set{}
+	set.RRule(until)
+	set.ExDate(time.Date(2024, 10, 31, 9, 0, 0, 0, time.UTC))
+	assert.True(t, set.IsFinite())
+	last, err = set.Last()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 8, 31, 9, 0, 0, 0, time.UTC), last)
+	set.RDate(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	last, _ = set.Last()
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), last)
+
+	set.RRule(infinite)
+	assert.False(t, set.IsFinite())
+	_, err = set.Last()
+	assert.ErrorIs(t, err, ErrInfinite)
+}
+
+func BenchmarkSetIterator(b *testing.B) {
+	dtstart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, k := range []int{1, 10, 100, 1000} {
+		r, _ := NewRRule(ROption{Freq: Hourly, Dtstart: dtstart})
+		set := Set{}
+		set.RRule(r)
+		for i := 0; i < k; i++ {
+			set.RDate(dtstart.Add(time.Duration(i)*time.Hour + time.Minute))
+			set.ExDate(dtstart.Add(time.Duration(2*i) * time.Hour))
+		}
+		b.Run(fmt.Sprintf("%d dates", k), func(b *testing.B) {
+			next := set.Iterator()
+			b.ResetTimer()
+			// Each operation generates one occurrence.
+			for i := 0; i < b.N; i++ {
+				if _, ok := next(); !ok {
+					b.Fatal("expected an occurrence")
+				}
+			}
+		})
+	}
+}
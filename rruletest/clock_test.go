@@ -0,0 +1,85 @@
+package rruletest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	rrule "github.com/kiraxie/rrule-go"
+)
+
+func TestFakeClock(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(now)
+	assert.Equal(t, now, clock.Now())
+
+	fired := clock.NewTimer(time.Minute)
+	stopped := clock.NewTimer(time.Minute)
+	later := clock.NewTimer(time.Hour)
+	clock.BlockUntil(3)
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, now.Add(time.Minute), <-fired.C())
+	assert.Len(t, stopped.C(), 0)
+	assert.Len(t, later.C(), 0)
+	clock.Set(now.Add(2 * time.Hour))
+	assert.Equal(t, now.Add(2*time.Hour), <-later.C())
+	assert.Equal(t, now.Add(2*time.Hour), <-clock.NewTimer(0).C())
+}
+
+func TestDefaultDTStart(t *testing.T) {
+	t.Parallel()
+	loc, _ := time.LoadLocation("Asia/Taipei")
+	clock := NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 500, loc))
+	want := time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)
+
+	r, err := rrule.NewRRule(rrule.ROption{Freq: rrule.Daily, Count: 2, Clock: clock})
+	assert.NoError(t, err)
+	assert.Equal(t, want, r.GetDTStart())
+
+	r, err = rrule.StrToRRuleWithClock("FREQ=DAILY;COUNT=2", clock)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{want, want.AddDate(0, 0, 1)}, r.All())
+
+	set, err := rrule.StrToRRuleSetWithClock("RRULE:FREQ=DAILY;COUNT=2", clock)
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Time{want, want.AddDate(0, 0, 1)}, set.All())
+}
+
+func TestScheduler(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(now)
+	r, _ := rrule.NewRRule(rrule.ROption{Freq: rrule.Hourly, Count: 4, Dtstart: now.Add(time.Hour)})
+
+	s := rrule.NewSchedulerWithClock(clock)
+	onTime := make(chan time.Time, 4)
+	coalesced := make(chan time.Time, 4)
+	s.Add(rrule.Job{Source: r, Handler: func(ctx context.Context, dt time.Time) { onTime <- dt }})
+	s.Add(rrule.Job{Source: r, MissedRun: rrule.Coalesce, Handler: func(ctx context.Context, dt time.Time) { coalesced <- dt }})
+	done := make(chan error)
+	go func() { done <- s.Run(context.Background()) }()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	assert.Equal(t, now.Add(time.Hour), <-onTime)
+	assert.Equal(t, now.Add(time.Hour), <-coalesced)
+
+	// The scheduler was down for two hours, the default policy catches up.
+	clock.BlockUntil(1)
+	clock.Advance(3 * time.Hour)
+	assert.Equal(t, now.Add(2*time.Hour), <-onTime)
+	assert.Equal(t, now.Add(3*time.Hour), <-onTime)
+	assert.Equal(t, now.Add(4*time.Hour), <-onTime)
+	assert.Equal(t, now.Add(4*time.Hour), <-coalesced)
+
+	assert.NoError(t, s.Shutdown(context.Background()))
+	assert.ErrorIs(t, <-done, rrule.ErrSchedulerStopped)
+	assert.Len(t, onTime, 0)
+	assert.Len(t, coalesced, 0)
+}
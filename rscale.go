@@ -0,0 +1,159 @@
+package rrule
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CalendarSystem is a non-Gregorian calendar selected by the RSCALE rule part
+// of RFC 7529. The generator works on calendar years: masks are built from
+// Months and anchored to the Gregorian date returned by YearStart, so every
+// BY* rule part is interpreted in the calendar system.
+type CalendarSystem interface {
+	// Name returns the RSCALE value of the calendar system, e.g. "HEBREW".
+	Name() string
+	// FromGregorian returns the calendar year, the ordinal month (1-based
+	// position of the month within the year, counting leap months) and the
+	// day of month of a Gregorian date.
+	FromGregorian(year int, month time.Month, day int) (cyear, cmonth, cday int)
+	// YearStart returns the Gregorian date of the first day of a calendar year.
+	YearStart(cyear int) (year int, month time.Month, day int)
+	// Months returns the months of a calendar year in order.
+	Months(cyear int) []CalendarMonth
+}
+
+// CalendarMonth describes a month of a calendar year.
+type CalendarMonth struct {
+	// Month is the month number used by BYMONTH.
+	Month int
+	// Leap reports a leap month, written with an "L" suffix like 5L.
+	Leap bool
+	// Days is the number of days in the month.
+	Days int
+}
+
+// label returns the internal month label stored in the masks and in
+// RRule.bymonth, leap months being negative.
+func (m CalendarMonth) label() int {
+	if m.Leap {
+		return -m.Month
+	}
+	return m.Month
+}
+
+var calendarSystems = struct {
+	sync.RWMutex
+	m map[string]CalendarSystem
+}{m: map[string]CalendarSystem{}}
+
+func init() {
+	RegisterCalendarSystem(Gregorian)
+	RegisterCalendarSystem(Chinese)
+	RegisterCalendarSystem(Hebrew)
+}
+
+// RegisterCalendarSystem makes a calendar system available to RSCALE,
+// replacing any system registered under the same name.
+func RegisterCalendarSystem(cs CalendarSystem) {
+	calendarSystems.Lock()
+	defer calendarSystems.Unlock()
+	calendarSystems.m[strings.ToUpper(cs.Name())] = cs
+}
+
+// LookupCalendarSystem returns the calendar system registered for an RSCALE value.
+func LookupCalendarSystem(rscale string) (CalendarSystem, bool) {
+	calendarSystems.RLock()
+	defer calendarSystems.RUnlock()
+	cs, ok := calendarSystems.m[strings.ToUpper(rscale)]
+	return cs, ok
+}
+
+// calendarSystemOf returns the calendar system of rscale, nil for the
+// Gregorian calendar which runs on the precomputed masks.
+func calendarSystemOf(rscale string) (CalendarSystem, error) {
+	if rscale == "" || strings.EqualFold(rscale, Gregorian.Name()) {
+		return nil, nil
+	}
+	cs, ok := LookupCalendarSystem(rscale)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedRscale, rscale)
+	}
+	return cs, nil
+}
+
+// Gregorian is the calendar system of RFC 5545, RSCALE=GREGORIAN.
+var Gregorian CalendarSystem = gregorian{}
+
+type gregorian struct{}
+
+func (gregorian) Name() string {
+	return "GREGORIAN"
+}
+
+func (gregorian) FromGregorian(year int, month time.Month, day int) (int, int, int) {
+	return year, int(month), day
+}
+
+func (gregorian) YearStart(year int) (int, time.Month, int) {
+	return year, time.January, 1
+}
+
+func (gregorian) Months(year int) []CalendarMonth {
+	months := make([]CalendarMonth, 12)
+	for i := range months {
+		months[i] = CalendarMonth{Month: i + 1, Days: daysIn(time.Month(i+1), year)}
+	}
+	return months
+}
+
+// calendarYear holds the masks of one calendar year, mirroring the
+// precomputed Gregorian masks in const.go.
+type calendarYear struct {
+	firstyday time.Time
+	yearlen   int
+	months    []CalendarMonth
+	mmask     []int
+	mrange    []int
+	mdaymask  []int
+	nmdaymask []int
+}
+
+func newCalendarYear(cs CalendarSystem, year int, loc *time.Location) *calendarYear {
+	y, m, d := cs.YearStart(year)
+	cy := &calendarYear{
+		firstyday: time.Date(y, m, d, 0, 0, 0, 0, loc),
+		months:    cs.Months(year),
+		mrange:    []int{0},
+	}
+	for _, month := range cy.months {
+		cy.yearlen += month.Days
+		cy.mrange = append(cy.mrange, cy.yearlen)
+		for day := 1; day <= month.Days; day++ {
+			cy.mmask = append(cy.mmask, month.label())
+			cy.mdaymask = append(cy.mdaymask, day)
+			cy.nmdaymask = append(cy.nmdaymask, day-month.Days-1)
+		}
+	}
+	// Every mask is 7 days longer to handle cross-year weekly periods.
+	next := cs.Months(year + 1)[0]
+	for day := 1; day <= 7; day++ {
+		cy.mmask = append(cy.mmask, next.label())
+		cy.mdaymask = append(cy.mdaymask, day)
+		cy.nmdaymask = append(cy.nmdaymask, day-next.Days-1)
+	}
+	return cy
+}
+
+// fixedFromDate returns the fixed day number (R.D., 0001-01-01 is 1) of a
+// Gregorian date as used by Calendrical Calculations.
+func fixedFromDate(year int, month time.Month, day int) int {
+	days, _ := divmod(int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix()), 86400)
+	return days + 719163
+}
+
+// dateFromFixed is the inverse of fixedFromDate.
+func dateFromFixed(fixed int) (int, time.Month, int) {
+	return time.Unix(int64(fixed-719163)*86400, 0).UTC().Date()
+}
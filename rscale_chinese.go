@@ -0,0 +1,302 @@
+package rrule
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Chinese is the astronomical Chinese lunisolar calendar, RSCALE=CHINESE.
+// Years are numbered by the Gregorian year in which they begin, months from
+// 1 to 12 and a leap month repeats the number of the month it follows.
+// New moons and solar terms follow Meeus' Astronomical Algorithms, so dates
+// are reliable for the Gregorian years 1900 to 2100.
+var Chinese CalendarSystem = &chinese{}
+
+type chinese struct {
+	years sync.Map // int -> []chineseMonth
+}
+
+type chineseMonth struct {
+	CalendarMonth
+	start int
+}
+
+const meanSynodicMonth = 29.530588861
+
+func (*chinese) Name() string {
+	return "CHINESE"
+}
+
+func (c *chinese) FromGregorian(year int, month time.Month, day int) (int, int, int) {
+	fixed := fixedFromDate(year, month, day)
+	cyear := year
+	if fixed < c.months(cyear)[0].start {
+		cyear--
+	}
+	months := c.months(cyear)
+	for i := len(months) - 1; i >= 0; i-- {
+		if fixed >= months[i].start {
+			return cyear, i + 1, fixed - months[i].start + 1
+		}
+	}
+	panic("unreachable")
+}
+
+func (c *chinese) YearStart(cyear int) (int, time.Month, int) {
+	return dateFromFixed(c.months(cyear)[0].start)
+}
+
+func (c *chinese) Months(cyear int) []CalendarMonth {
+	months := c.months(cyear)
+	result := make([]CalendarMonth, len(months))
+	for i, m := range months {
+		result[i] = m.CalendarMonth
+	}
+	return result
+}
+
+func (c *chinese) months(cyear int) []chineseMonth {
+	if v, ok := c.years.Load(cyear); ok {
+		return v.([]chineseMonth)
+	}
+	start := chineseNewYearOnOrBefore(fixedFromDate(cyear, time.July, 1))
+	end := chineseNewYearOnOrBefore(fixedFromDate(cyear+1, time.July, 1))
+	var months []chineseMonth
+	for m := start; m < end; m = chineseNewMoonOnOrAfter(m + 1) {
+		month, leap := chineseMonthOf(m)
+		months = append(months, chineseMonth{start: m})
+		months[len(months)-1].Month = month
+		months[len(months)-1].Leap = leap
+	}
+	for i := range months {
+		next := end
+		if i+1 < len(months) {
+			next = months[i+1].start
+		}
+		months[i].Days = next - months[i].start
+	}
+	v, _ := c.years.LoadOrStore(cyear, months)
+	return v.([]chineseMonth)
+}
+
+// chineseMonthOf returns the month number and leap flag of the month
+// starting on the fixed date m.
+func chineseMonthOf(m int) (int, bool) {
+	s1 := chineseWinterSolsticeOnOrBefore(m)
+	s2 := chineseWinterSolsticeOnOrBefore(s1 + 370)
+	m12 := chineseNewMoonOnOrAfter(s1 + 1)
+	nextM11 := chineseNewMoonBefore(s2 + 1)
+	leapYear := math.Round(float64(nextM11-m12)/meanSynodicMonth) == 12
+	month := int(math.Round(float64(m-m12) / meanSynodicMonth))
+	if leapYear && chinesePriorLeapMonth(m12, m) {
+		month--
+	}
+	month = amod(month, 12)
+	leap := leapYear && chineseNoMajorSolarTerm(m) &&
+		!chinesePriorLeapMonth(m12, chineseNewMoonBefore(m))
+	return month, leap
+}
+
+func chineseNewYearInSui(date int) int {
+	s1 := chineseWinterSolsticeOnOrBefore(date)
+	s2 := chineseWinterSolsticeOnOrBefore(s1 + 370)
+	m12 := chineseNewMoonOnOrAfter(s1 + 1)
+	m13 := chineseNewMoonOnOrAfter(m12 + 1)
+	nextM11 := chineseNewMoonBefore(s2 + 1)
+	if math.Round(float64(nextM11-m12)/meanSynodicMonth) == 12 &&
+		(chineseNoMajorSolarTerm(m12) || chineseNoMajorSolarTerm(m13)) {
+		return chineseNewMoonOnOrAfter(m13 + 1)
+	}
+	return m13
+}
+
+func chineseNewYearOnOrBefore(date int) int {
+	newYear := chineseNewYearInSui(date)
+	if date >= newYear {
+		return newYear
+	}
+	return chineseNewYearInSui(date - 180)
+}
+
+func chinesePriorLeapMonth(mPrime, m int) bool {
+	for m >= mPrime {
+		if chineseNoMajorSolarTerm(m) {
+			return true
+		}
+		m = chineseNewMoonBefore(m)
+	}
+	return false
+}
+
+func chineseNoMajorSolarTerm(date int) bool {
+	return chineseMajorSolarTerm(date) == chineseMajorSolarTerm(chineseNewMoonOnOrAfter(date+1))
+}
+
+func chineseMajorSolarTerm(date int) int {
+	s := solarLongitude(chineseMidnight(date))
+	return amod(2+int(math.Floor(s/30)), 12)
+}
+
+func chineseWinterSolsticeOnOrBefore(date int) int {
+	const winter = 270
+	tau := chineseMidnight(date + 1)
+	rate := 365.242189 / 360
+	tau0 := tau - rate*math.Mod(solarLongitude(tau)-winter+360, 360)
+	delta := math.Mod(solarLongitude(tau0)-winter+540, 360) - 180
+	approx := math.Min(tau, tau0-rate*delta)
+	day := int(math.Floor(approx)) - 1
+	for winter >= solarLongitude(chineseMidnight(day+1)) {
+		day++
+	}
+	return day
+}
+
+func chineseNewMoonOnOrAfter(date int) int {
+	return chineseFixed(newMoonAtOrAfter(chineseMidnight(date)))
+}
+
+func chineseNewMoonBefore(date int) int {
+	return chineseFixed(newMoonBefore(chineseMidnight(date)))
+}
+
+// chineseZone returns the offset of Chinese time from UT in days, which
+// was the Beijing mean solar time before 1929.
+func chineseZone(moment float64) float64 {
+	if year, _, _ := dateFromFixed(int(math.Floor(moment))); year < 1929 {
+		return 1397.0 / 180 / 24
+	}
+	return 8.0 / 24
+}
+
+// chineseMidnight returns the universal moment of midnight in China
+// starting the fixed date.
+func chineseMidnight(date int) float64 {
+	return float64(date) - chineseZone(float64(date))
+}
+
+// chineseFixed returns the fixed date in China of a universal moment.
+func chineseFixed(moment float64) int {
+	return int(math.Floor(moment + chineseZone(moment)))
+}
+
+func amod(a, b int) int {
+	return b + pymod(a, -b)
+}
+
+// Universal moments below are fixed dates with a fraction of day, the
+// astronomical formulas use Julian ephemeris days.
+const fixedToJD = 1721424.5
+
+// ephemerisCorrection returns the difference between dynamical and
+// universal time in days, after Espenak and Meeus.
+func ephemerisCorrection(moment float64) float64 {
+	year, _, _ := dateFromFixed(int(math.Floor(moment)))
+	y := float64(year) + 0.5
+	var seconds float64
+	switch {
+	case year < 1900:
+		u := (y - 1820) / 100
+		seconds = -20 + 32*u*u
+	case year < 1920:
+		t := y - 1900
+		seconds = -2.79 + 1.494119*t - 0.0598939*t*t + 0.0061966*t*t*t - 0.000197*t*t*t*t
+	case year < 1941:
+		t := y - 1920
+		seconds = 21.20 + 0.84493*t - 0.076100*t*t + 0.0020936*t*t*t
+	case year < 1961:
+		t := y - 1950
+		seconds = 29.07 + 0.407*t - t*t/233 + t*t*t/2547
+	case year < 1986:
+		t := y - 1975
+		seconds = 45.45 + 1.067*t - t*t/260 - t*t*t/718
+	case year < 2005:
+		t := y - 2000
+		seconds = 63.86 + 0.3345*t - 0.060374*t*t + 0.0017275*t*t*t + 0.000651814*t*t*t*t + 0.00002373599*t*t*t*t*t
+	case year < 2050:
+		t := y - 2000
+		seconds = 62.92 + 0.32217*t + 0.005589*t*t
+	case year < 2150:
+		u := (y - 1820) / 100
+		seconds = -20 + 32*u*u - 0.5628*(2150-y)
+	default:
+		u := (y - 1820) / 100
+		seconds = -20 + 32*u*u
+	}
+	return seconds / 86400
+}
+
+func sinDeg(deg float64) float64 {
+	return math.Sin(deg * math.Pi / 180)
+}
+
+// solarLongitude returns the apparent longitude of the sun in degrees at a
+// universal moment.
+func solarLongitude(moment float64) float64 {
+	t := (moment + ephemerisCorrection(moment) + fixedToJD - 2451545.0) / 36525
+	l0 := 280.46646 + 36000.76983*t + 0.0003032*t*t
+	m := 357.52911 + 35999.05029*t - 0.0001537*t*t
+	c := (1.914602-0.004817*t-0.000014*t*t)*sinDeg(m) +
+		(0.019993-0.000101*t)*sinDeg(2*m) +
+		0.000289*sinDeg(3*m)
+	omega := 125.04 - 1934.136*t
+	lambda := l0 + c - 0.00569 - 0.00478*sinDeg(omega)
+	return math.Mod(math.Mod(lambda, 360)+360, 360)
+}
+
+// nthNewMoon returns the universal moment of the kth new moon after the one
+// of January 6, 2000.
+func nthNewMoon(k int) float64 {
+	kf := float64(k)
+	t := kf / 1236.85
+	jde := 2451550.09766 + meanSynodicMonth*kf +
+		0.00015437*t*t - 0.000000150*t*t*t + 0.00000000073*t*t*t*t
+	e := 1 - 0.002516*t - 0.0000074*t*t
+	m := 2.5534 + 29.10535670*kf - 0.0000014*t*t - 0.00000011*t*t*t
+	mp := 201.5643 + 385.81693528*kf + 0.0107582*t*t + 0.00001238*t*t*t - 0.000000058*t*t*t*t
+	f := 160.7108 + 390.67050284*kf - 0.0016118*t*t - 0.00000227*t*t*t + 0.000000011*t*t*t*t
+	omega := 124.7746 - 1.56375588*kf + 0.0020672*t*t + 0.00000215*t*t*t
+	jde += -0.40720*sinDeg(mp) +
+		0.17241*e*sinDeg(m) +
+		0.01608*sinDeg(2*mp) +
+		0.01039*sinDeg(2*f) +
+		0.00739*e*sinDeg(mp-m) -
+		0.00514*e*sinDeg(mp+m) +
+		0.00208*e*e*sinDeg(2*m) -
+		0.00111*sinDeg(mp-2*f) -
+		0.00057*sinDeg(mp+2*f) +
+		0.00056*e*sinDeg(2*mp+m) -
+		0.00042*sinDeg(3*mp) +
+		0.00042*e*sinDeg(m+2*f) +
+		0.00038*e*sinDeg(m-2*f) -
+		0.00024*e*sinDeg(2*mp-m) -
+		0.00017*sinDeg(omega) -
+		0.00007*sinDeg(mp+2*m) +
+		0.00004*sinDeg(2*mp-2*f) +
+		0.00004*sinDeg(3*m) +
+		0.00003*sinDeg(mp+m-2*f) +
+		0.00003*sinDeg(2*mp+2*f) -
+		0.00003*sinDeg(mp+m+2*f) +
+		0.00003*sinDeg(mp-m+2*f) -
+		0.00002*sinDeg(mp-m-2*f) -
+		0.00002*sinDeg(3*mp+m) +
+		0.00002*sinDeg(4*mp)
+	moment := jde - fixedToJD
+	return moment - ephemerisCorrection(moment)
+}
+
+func newMoonAtOrAfter(moment float64) float64 {
+	k := int(math.Floor((moment+fixedToJD-2451550.09766)/meanSynodicMonth)) - 1
+	for nthNewMoon(k) < moment {
+		k++
+	}
+	return nthNewMoon(k)
+}
+
+func newMoonBefore(moment float64) float64 {
+	k := int(math.Floor((moment+fixedToJD-2451550.09766)/meanSynodicMonth)) + 1
+	for nthNewMoon(k) >= moment {
+		k--
+	}
+	return nthNewMoon(k)
+}
@@ -0,0 +1,99 @@
+package rrule
+
+import "time"
+
+// Hebrew is the arithmetic Hebrew calendar, RSCALE=HEBREW.
+// Months are numbered from Tishri (1) to Elul (12) as in RFC 7529, and the
+// leap month Adar I is 5L.
+var Hebrew CalendarSystem = hebrew{}
+
+// hebrewEpoch is the fixed date of 1 Tishri AM 1.
+const hebrewEpoch = -1373427
+
+type hebrew struct{}
+
+func (hebrew) Name() string {
+	return "HEBREW"
+}
+
+func (h hebrew) FromGregorian(year int, month time.Month, day int) (int, int, int) {
+	fixed := fixedFromDate(year, month, day)
+	// AM year starts in autumn, 3760 or 3761 years before the Gregorian year.
+	cyear := year + 3760
+	if fixed >= hebrewNewYear(cyear+1) {
+		cyear++
+	}
+	days := fixed - hebrewNewYear(cyear)
+	for i, m := range h.Months(cyear) {
+		if days < m.Days {
+			return cyear, i + 1, days + 1
+		}
+		days -= m.Days
+	}
+	panic("unreachable")
+}
+
+func (hebrew) YearStart(cyear int) (int, time.Month, int) {
+	return dateFromFixed(hebrewNewYear(cyear))
+}
+
+func (hebrew) Months(cyear int) []CalendarMonth {
+	yearlen := hebrewNewYear(cyear+1) - hebrewNewYear(cyear)
+	heshvan, kislev := 29, 30
+	switch yearlen % 10 {
+	case 3: // deficient year
+		kislev = 29
+	case 5: // complete year
+		heshvan = 30
+	}
+	months := []CalendarMonth{
+		{Month: 1, Days: 30},
+		{Month: 2, Days: heshvan},
+		{Month: 3, Days: kislev},
+		{Month: 4, Days: 29},
+		{Month: 5, Days: 30},
+	}
+	if hebrewLeapYear(cyear) {
+		months = append(months, CalendarMonth{Month: 5, Leap: true, Days: 30})
+	}
+	return append(months,
+		CalendarMonth{Month: 6, Days: 29},
+		CalendarMonth{Month: 7, Days: 30},
+		CalendarMonth{Month: 8, Days: 29},
+		CalendarMonth{Month: 9, Days: 30},
+		CalendarMonth{Month: 10, Days: 29},
+		CalendarMonth{Month: 11, Days: 30},
+		CalendarMonth{Month: 12, Days: 29},
+	)
+}
+
+func hebrewLeapYear(cyear int) bool {
+	return pymod(7*cyear+1, 19) < 7
+}
+
+// hebrewElapsedDays returns the days from the epoch to the molad of Tishri,
+// postponed when it falls on Sunday, Wednesday or Friday.
+func hebrewElapsedDays(cyear int) int {
+	monthsElapsed, _ := divmod(235*cyear-234, 19)
+	partsElapsed := 12084 + 13753*monthsElapsed
+	dayParts, _ := divmod(partsElapsed, 25920)
+	days := 29*monthsElapsed + dayParts
+	if pymod(3*(days+1), 7) < 3 {
+		return days + 1
+	}
+	return days
+}
+
+// hebrewNewYear returns the fixed date of 1 Tishri.
+func hebrewNewYear(cyear int) int {
+	ny0 := hebrewElapsedDays(cyear - 1)
+	ny1 := hebrewElapsedDays(cyear)
+	ny2 := hebrewElapsedDays(cyear + 1)
+	correction := 0
+	if ny2-ny1 == 356 {
+		correction = 2
+	} else if ny1-ny0 == 382 {
+		correction = 1
+	}
+	return hebrewEpoch + ny1 + correction
+}
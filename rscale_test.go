@@ -0,0 +1,226 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChineseCalendar(t *testing.T) {
+	t.Parallel()
+	newYears := map[int]time.Time{
+		2017: time.Date(2017, 1, 28, 0, 0, 0, 0, time.UTC),
+		2020: time.Date(2020, 1, 25, 0, 0, 0, 0, time.UTC),
+		2023: time.Date(2023, 1, 22, 0, 0, 0, 0, time.UTC),
+		2024: time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC),
+		2033: time.Date(2033, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	leapMonths := map[int]int{2017: 6, 2020: 4, 2023: 2, 2024: 0, 2033: 11}
+	for year, want := range newYears {
+		y, m, d := Chinese.YearStart(year)
+		assert.Equal(t, want, time.Date(y, m, d, 0, 0, 0, 0, time.UTC))
+		leap := 0
+		for _, month := range Chinese.Months(year) {
+			if month.Leap {
+				leap = month.Month
+			}
+		}
+		assert.Equal(t, leapMonths[year], leap, "leap month of %d", year)
+	}
+	cyear, cmonth, cday := Chinese.FromGregorian(2023, time.September, 29)
+	assert.Equal(t, []int{2023, 9, 15}, []int{cyear, cmonth, cday})
+}
+
+func TestHebrewCalendar(t *testing.T) {
+	t.Parallel()
+	for year, want := range map[int]time.Time{
+		5783: time.Date(2022, 9, 26, 0, 0, 0, 0, time.UTC),
+		5784: time.Date(2023, 9, 16, 0, 0, 0, 0, time.UTC),
+		5785: time.Date(2024, 10, 3, 0, 0, 0, 0, time.UTC),
+	} {
+		y, m, d := Hebrew.YearStart(year)
+		assert.Equal(t, want, time.Date(y, m, d, 0, 0, 0, 0, time.UTC))
+	}
+	assert.Len(t, Hebrew.Months(5784), 13)
+	assert.Len(t, Hebrew.Months(5785), 12)
+	cyear, cmonth, cday := Hebrew.FromGregorian(2024, time.April, 23)
+	assert.Equal(t, []int{5784, 8, 15}, []int{cyear, cmonth, cday})
+}
+
+func TestRscaleChineseYearly(t *testing.T) {
+	t.Parallel()
+	r, err := StrToRRule("DTSTART:20230929T000000Z\nRRULE:RSCALE=CHINESE;FREQ=YEARLY;COUNT=4")
+	assert.NoError(t, err)
+	want := []time.Time{
+		time.Date(2023, 9, 29, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 9, 17, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 9, 25, 0, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, want, r.All())
+	assert.Equal(t, "DTSTART:20230929T000000Z\nRRULE:RSCALE=CHINESE;FREQ=YEARLY;COUNT=4", r.String())
+}
+
+func TestRscaleChineseMonthly(t *testing.T) {
+	t.Parallel()
+	// The first day of each lunar month, including the leap 2nd month of 2023.
+	r, err := NewRRule(ROption{
+		Rscale:     "CHINESE",
+		Freq:       Monthly,
+		Count:      4,
+		Bymonthday: []int{1},
+		Dtstart:    time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	want := []time.Time{
+		time.Date(2023, 2, 20, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 3, 22, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 4, 20, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 5, 19, 0, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, want, r.All())
+}
+
+func TestRscaleHebrewLeapMonthSkip(t *testing.T) {
+	t.Parallel()
+	// 8 Adar I, or 8 Adar (FORWARD) / 8 Shevat (BACKWARD) in common years.
+	for _, tc := range []struct {
+		rule string
+		want []time.Time
+	}{
+		{
+			rule: "RSCALE=HEBREW;FREQ=YEARLY;COUNT=2;BYMONTH=5L;BYMONTHDAY=8",
+			want: []time.Time{
+				time.Date(2024, 2, 17, 0, 0, 0, 0, time.UTC),
+				time.Date(2027, 2, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			rule: "RSCALE=HEBREW;FREQ=YEARLY;COUNT=2;SKIP=FORWARD;BYMONTH=5L;BYMONTHDAY=8",
+			want: []time.Time{
+				time.Date(2024, 2, 17, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			rule: "RSCALE=HEBREW;FREQ=YEARLY;COUNT=2;SKIP=BACKWARD;BYMONTH=5L;BYMONTHDAY=8",
+			want: []time.Time{
+				time.Date(2024, 2, 17, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 2, 6, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	} {
+		r, err := StrToRRule("DTSTART:20231001T000000Z\nRRULE:" + tc.rule)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.want, r.All(), tc.rule)
+		assert.Equal(t, tc.rule, r.OrigOptions.RRuleString())
+	}
+}
+
+func TestRscaleDailyMatchesGregorian(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2023, 9, 10, 9, 0, 0, 0, time.UTC)
+	for _, freq := range []Frequency{Weekly, Daily, Hourly} {
+		gregorian, _ := NewRRule(ROption{Freq: freq, Interval: 5, Count: 100, Dtstart: dtstart})
+		hebrew, _ := NewRRule(ROption{Freq: freq, Interval: 5, Count: 100, Dtstart: dtstart, Rscale: "HEBREW"})
+		assert.Equal(t, gregorian.All(), hebrew.All(), freq.String())
+	}
+}
+
+func TestRscaleInvalid(t *testing.T) {
+	t.Parallel()
+	_, err := StrToRRule("RSCALE=MARTIAN;FREQ=YEARLY")
+	assert.ErrorIs(t, err, ErrUnsupportedRscale)
+	_, err = NewRRule(ROption{Freq: Yearly, Rscale: "MARTIAN"})
+	assert.ErrorIs(t, err, ErrUnsupportedRscale)
+	_, err = NewRRule(ROption{Freq: Yearly, Rscale: "CHINESE", Byeaster: []int{0}})
+	assert.ErrorIs(t, err, ErrUnsupportedRscale)
+	_, err = StrToRRule("FREQ=YEARLY;SKIP=SIDEWAYS")
+	assert.ErrorIs(t, err, ErrInvalidSkip)
+	_, err = NewRRule(ROption{Freq: Yearly, Rscale: "HEBREW", Byleapmonth: []int{13}})
+	assert.ErrorIs(t, err, ErrInvalidateBound)
+	assert.ErrorContains(t, err, "byleapmonth")
+}
+
+func TestSkipMonthDay(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2023, 1, 1, 9, 0, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 9, 0, 0, 0, time.UTC)
+	}
+	for _, tc := range []struct {
+		desc   string
+		option ROption
+		want   []time.Time
+	}{
+		{
+			desc:   "monthly backward",
+			option: ROption{Freq: Monthly, Count: 5, Bymonthday: []int{31}, Skip: Backward},
+			want:   []time.Time{day(2023, 1, 31), day(2023, 2, 28), day(2023, 3, 31), day(2023, 4, 30), day(2023, 5, 31)},
+		},
+		{
+			desc:   "monthly forward",
+			option: ROption{Freq: Monthly, Count: 5, Bymonthday: []int{31}, Skip: Forward},
+			want:   []time.Time{day(2023, 1, 31), day(2023, 3, 1), day(2023, 3, 31), day(2023, 5, 1), day(2023, 5, 31)},
+		},
+		{
+			desc:   "monthly forward with interval",
+			option: ROption{Freq: Monthly, Interval: 2, Count: 4, Bymonthday: []int{31}, Skip: Forward, Dtstart: day(2023, 7, 1)},
+			want:   []time.Time{day(2023, 7, 31), day(2023, 10, 1), day(2023, 12, 1), day(2024, 1, 31)},
+		},
+		{
+			desc:   "monthly forward collides",
+			option: ROption{Freq: Monthly, Count: 5, Bymonthday: []int{1, 30, 31}, Skip: Forward, Dtstart: day(2023, 2, 1)},
+			want:   []time.Time{day(2023, 2, 1), day(2023, 3, 1), day(2023, 3, 30), day(2023, 3, 31), day(2023, 4, 1)},
+		},
+		{
+			desc:   "monthly negative",
+			option: ROption{Freq: Monthly, Count: 4, Bymonthday: []int{-31}, Skip: Backward, Dtstart: day(2023, 3, 1)},
+			want:   []time.Time{day(2023, 3, 1), day(2023, 3, 31), day(2023, 5, 1), day(2023, 5, 31)},
+		},
+		{
+			desc:   "yearly leap day",
+			option: ROption{Freq: Yearly, Count: 3, Bymonth: []int{2}, Bymonthday: []int{29}, Skip: Backward},
+			want:   []time.Time{day(2023, 2, 28), day(2024, 2, 29), day(2025, 2, 28)},
+		},
+		{
+			desc:   "yearly leap day forward",
+			option: ROption{Freq: Yearly, Count: 3, Bymonth: []int{2}, Bymonthday: []int{29}, Skip: Forward},
+			want:   []time.Time{day(2023, 3, 1), day(2024, 2, 29), day(2025, 3, 1)},
+		},
+		{
+			desc:   "daily forward",
+			option: ROption{Freq: Daily, Count: 4, Bymonthday: []int{31}, Skip: Forward},
+			want:   []time.Time{day(2023, 1, 31), day(2023, 3, 1), day(2023, 3, 31), day(2023, 5, 1)},
+		},
+		{
+			desc:   "substitute filtered by weekday",
+			option: ROption{Freq: Monthly, Count: 3, Bymonthday: []int{31}, Byweekday: []Weekday{Tuesday}, Skip: Backward},
+			want:   []time.Time{day(2023, 1, 31), day(2023, 2, 28), day(2023, 10, 31)},
+		},
+	} {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			if tc.option.Dtstart.IsZero() {
+				tc.option.Dtstart = dtstart
+			}
+			r, err := NewRRule(tc.option)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, r.All())
+		})
+	}
+}
+
+func TestSkipString(t *testing.T) {
+	t.Parallel()
+	option, err := StrToROption("FREQ=MONTHLY;BYMONTHDAY=31;SKIP=BACKWARD")
+	assert.NoError(t, err)
+	assert.Equal(t, Backward, option.Skip)
+	want := "RSCALE=GREGORIAN;FREQ=MONTHLY;SKIP=BACKWARD;BYMONTHDAY=31"
+	assert.Equal(t, want, option.RRuleString())
+	option, err = StrToROption(want)
+	assert.NoError(t, err)
+	assert.Equal(t, want, option.RRuleString())
+}
@@ -0,0 +1,228 @@
+package rrule
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultGrace is the lateness up to which an occurrence is not missed.
+const defaultGrace = time.Second
+
+// Handler is called by a Scheduler on an occurrence of a job. ctx is
+// canceled when a Shutdown times out.
+type Handler func(ctx context.Context, occurrence time.Time)
+
+// Job is a recurrence source whose occurrences run a handler.
+type Job struct {
+	Source  Iterable
+	Handler Handler
+	// MissedRun tells how the occurrences later than Grace are handled.
+	MissedRun MissedRun
+	// Grace is the lateness up to which an occurrence is not missed, one
+	// second when zero.
+	Grace time.Duration
+	// Since is the time of the last run before a downtime, the occurrences
+	// after it are handled by MissedRun. It defaults to the time the job is
+	// added, the earlier occurrences being ignored.
+	Since time.Time
+}
+
+// JobID identifies a job of a Scheduler.
+type JobID int
+
+type scheduledJob struct {
+	Job
+	next    Next
+	pending time.Time
+	ok      bool
+}
+
+// Scheduler runs the handlers of jobs on their occurrences. Each run of a
+// handler has its own goroutine, so runs of a job may overlap.
+type Scheduler struct {
+	mu     sync.Mutex
+	jobs   map[JobID]*scheduledJob
+	lastID JobID
+	wake   chan struct{}
+	// stopped is set by Shutdown under mu, so that no run starts after it.
+	stopped bool
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	running  sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
+	clock    Clock
+}
+
+// NewScheduler returns a scheduler without jobs, see Run.
+func NewScheduler() *Scheduler {
+	return NewSchedulerWithClock(SystemClock)
+}
+
+// NewSchedulerWithClock returns a scheduler without jobs timed by clock.
+func NewSchedulerWithClock(clock Clock) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		clock:  clockOrSystem(clock),
+		jobs:   map[JobID]*scheduledJob{},
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Add registers a job, which may be done while the scheduler runs.
+func (s *Scheduler) Add(job Job) JobID {
+	if job.Grace <= 0 {
+		job.Grace = defaultGrace
+	}
+	if job.Since.IsZero() {
+		job.Since = s.clock.Now()
+	}
+	j := &scheduledJob{Job: job, next: job.Source.Iterator()}
+	j.pending, j.ok = j.next()
+	for j.ok && !j.pending.After(job.Since) {
+		j.pending, j.ok = j.next()
+	}
+
+	s.mu.Lock()
+	s.lastID++
+	id := s.lastID
+	s.jobs[id] = j
+	s.mu.Unlock()
+	s.notify()
+	return id
+}
+
+// Remove unregisters a job, it reports whether the job was registered. Runs
+// of the job already started are not interrupted.
+func (s *Scheduler) Remove(id JobID) bool {
+	s.mu.Lock()
+	_, ok := s.jobs[id]
+	delete(s.jobs, id)
+	s.mu.Unlock()
+	s.notify()
+	return ok
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run runs the handlers of the jobs on their occurrences until ctx is done
+// or Shutdown is called. Jobs without further occurrences are removed.
+func (s *Scheduler) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-s.stop:
+			return ErrSchedulerStopped
+		case <-s.wake:
+			// fire sees the jobs changed so far, it need not run again.
+		default:
+		}
+		now := s.clock.Now()
+		next, ok := s.fire(now)
+		var timer Timer
+		var alarm <-chan time.Time
+		if ok {
+			timer = s.clock.NewTimer(next.Sub(now))
+			alarm = timer.C()
+		}
+		err := s.sleep(ctx, alarm)
+		if timer != nil {
+			timer.Stop()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits for the alarm or a change of the jobs.
+func (s *Scheduler) sleep(ctx context.Context, alarm <-chan time.Time) error {
+	select {
+	case <-alarm:
+		return nil
+	case <-s.wake:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.stop:
+		return ErrSchedulerStopped
+	}
+}
+
+// fire starts the runs of the occurrences due at now, it returns the next
+// occurrence of all jobs.
+func (s *Scheduler) fire(now time.Time) (next time.Time, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return time.Time{}, false
+	}
+	for id, j := range s.jobs {
+		var runs []time.Time
+		for ; j.ok && !j.pending.After(now); j.pending, j.ok = j.next() {
+			switch {
+			case j.MissedRun == Coalesce:
+				// A single run for the last due occurrence.
+				runs = append(runs[:0], j.pending)
+			case j.MissedRun == CatchUp || now.Sub(j.pending) <= j.Grace:
+				runs = append(runs, j.pending)
+			}
+		}
+		if len(runs) != 0 {
+			s.start(j.Handler, runs)
+		}
+		if !j.ok {
+			delete(s.jobs, id)
+			continue
+		}
+		if !ok || j.pending.Before(next) {
+			next, ok = j.pending, true
+		}
+	}
+	return next, ok
+}
+
+// start runs handler on the occurrences in order.
+func (s *Scheduler) start(handler Handler, runs []time.Time) {
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		for _, dt := range runs {
+			if s.ctx.Err() != nil {
+				return
+			}
+			handler(s.ctx, dt)
+		}
+	}()
+}
+
+// Shutdown stops Run and waits for the running handlers. When ctx is done
+// first, the context of the handlers is canceled and ctx.Err() is returned.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+	s.stopOnce.Do(func() { close(s.stop) })
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
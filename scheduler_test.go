@@ -0,0 +1,164 @@
+package rrule_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiraxie/rrule-go"
+	"github.com/kiraxie/rrule-go/rruletest"
+)
+
+// recorder records the occurrences a handler runs on.
+type recorder struct {
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func (r *recorder) handle(ctx context.Context, dt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.times = append(r.times, dt)
+}
+
+func (r *recorder) runs() []time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]time.Time(nil), r.times...)
+}
+
+// addLater adds a job due a day after now, so that the scheduler keeps
+// waiting on a timer of clock once the other jobs ran.
+func addLater(s *rrule.Scheduler, now time.Time) {
+	later, _ := rrule.NewRRule(rrule.ROption{Freq: rrule.Daily, Count: 1, Dtstart: now.AddDate(0, 0, 1)})
+	s.Add(rrule.Job{Source: later, Handler: func(ctx context.Context, dt time.Time) {}})
+}
+
+func TestSchedulerMissedRun(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := rruletest.NewFakeClock(now)
+	dtstart := now.Add(-time.Minute)
+	r, _ := rrule.NewRRule(rrule.ROption{Freq: rrule.Secondly, Interval: 10, Count: 5, Dtstart: dtstart})
+	occurrences := r.All()
+
+	s := rrule.NewSchedulerWithClock(clock)
+	var ids []rrule.JobID
+	recorders := map[rrule.MissedRun]*recorder{}
+	for _, policy := range []rrule.MissedRun{rrule.CatchUp, rrule.SkipMissed, rrule.Coalesce} {
+		recorders[policy] = &recorder{}
+		ids = append(ids, s.Add(rrule.Job{Source: r, Handler: recorders[policy].handle, MissedRun: policy, Since: dtstart}))
+	}
+	// Jobs are added after their Since.
+	ignored := &recorder{}
+	ids = append(ids, s.Add(rrule.Job{Source: r, Handler: ignored.handle}))
+	addLater(s, now)
+	done := make(chan error)
+	go func() { done <- s.Run(context.Background()) }()
+
+	clock.BlockUntil(1)
+	assert.NoError(t, s.Shutdown(context.Background()))
+	assert.ErrorIs(t, <-done, rrule.ErrSchedulerStopped)
+
+	assert.Equal(t, occurrences[1:], recorders[rrule.CatchUp].runs())
+	assert.Empty(t, recorders[rrule.SkipMissed].runs())
+	assert.Equal(t, occurrences[4:], recorders[rrule.Coalesce].runs())
+	assert.Empty(t, ignored.runs())
+	// Jobs without further occurrences are removed.
+	for _, id := range ids {
+		assert.False(t, s.Remove(id))
+	}
+}
+
+func TestSchedulerFire(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := rruletest.NewFakeClock(now)
+	r, _ := rrule.NewRRule(rrule.ROption{Freq: rrule.Hourly, Count: 4, Dtstart: now.Add(-2 * time.Hour)})
+
+	s := rrule.NewSchedulerWithClock(clock)
+	recorders := map[rrule.MissedRun]*recorder{}
+	for _, policy := range []rrule.MissedRun{rrule.CatchUp, rrule.SkipMissed, rrule.Coalesce} {
+		recorders[policy] = &recorder{}
+		s.Add(rrule.Job{Source: r, Handler: recorders[policy].handle, MissedRun: policy, Since: now.Add(-3 * time.Hour)})
+	}
+	addLater(s, now)
+	done := make(chan error)
+	go func() { done <- s.Run(context.Background()) }()
+
+	// The occurrence at now is within Grace, the two before are missed.
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	clock.BlockUntil(1)
+	assert.NoError(t, s.Shutdown(context.Background()))
+	assert.ErrorIs(t, <-done, rrule.ErrSchedulerStopped)
+
+	// The runs of each firing have their own goroutine.
+	assert.ElementsMatch(t, r.All(), recorders[rrule.CatchUp].runs())
+	assert.ElementsMatch(t, r.All()[2:], recorders[rrule.SkipMissed].runs())
+	// Coalesce runs once, for the last due occurrence even when it is on time.
+	assert.ElementsMatch(t, r.All()[2:], recorders[rrule.Coalesce].runs())
+}
+
+func TestSchedulerRun(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := rruletest.NewFakeClock(now)
+	r, _ := rrule.NewRRule(rrule.ROption{Freq: rrule.Secondly, Count: 2, Dtstart: now.Add(time.Second)})
+
+	s := rrule.NewSchedulerWithClock(clock)
+	removed := &recorder{}
+	assert.True(t, s.Remove(s.Add(rrule.Job{Source: r, Handler: removed.handle})))
+	assert.False(t, s.Remove(0))
+	rec := &recorder{}
+	s.Add(rrule.Job{Source: r, Handler: rec.handle})
+	addLater(s, now)
+	done := make(chan error)
+	go func() { done <- s.Run(context.Background()) }()
+
+	for range r.All() {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+	}
+	clock.BlockUntil(1)
+	assert.NoError(t, s.Shutdown(context.Background()))
+	assert.ErrorIs(t, <-done, rrule.ErrSchedulerStopped)
+	assert.ElementsMatch(t, r.All(), rec.runs())
+	assert.Empty(t, removed.runs())
+}
+
+func TestSchedulerShutdownTimeout(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	r, _ := rrule.NewRRule(rrule.ROption{Freq: rrule.Secondly, Count: 1, Dtstart: now.Add(-time.Hour)})
+	s := rrule.NewSchedulerWithClock(rruletest.NewFakeClock(now))
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+	s.Add(rrule.Job{Source: r, MissedRun: rrule.CatchUp, Since: now.Add(-2 * time.Hour), Handler: func(ctx context.Context, dt time.Time) {
+		close(started)
+		<-ctx.Done()
+		close(canceled)
+	}})
+	go func() { _ = s.Run(context.Background()) }()
+	<-started
+
+	// The handler runs until its context is canceled, past the one of Shutdown.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, s.Shutdown(ctx), context.Canceled)
+	<-canceled
+}
+
+func TestMissedRunParse(t *testing.T) {
+	t.Parallel()
+	for _, policy := range []rrule.MissedRun{rrule.CatchUp, rrule.SkipMissed, rrule.Coalesce} {
+		var parsed rrule.MissedRun
+		assert.NoError(t, parsed.Parse(policy.String()))
+		assert.Equal(t, policy, parsed)
+	}
+	var parsed rrule.MissedRun
+	assert.ErrorIs(t, parsed.Parse("LATER"), rrule.ErrInvalidMissedRun)
+}
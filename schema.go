@@ -0,0 +1,115 @@
+package rrule
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"strings"
+)
+
+// Migrations holds the postgres-rrule schema defining the _rrule.RRULE and
+// _rrule.RRULESET composite types read by Scan, as versioned
+// "<version>_<name>.up.sql" and ".down.sql" files in the migrations
+// directory, the layout of golang-migrate. A change of the schema is a new
+// pair of files with a later version, the released ones are never edited.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// SchemaVersion is the version of the latest migration of Migrations.
+const SchemaVersion = 20230702
+
+var (
+	// rruleColumns are the fields of the _rrule.RRULE composite in the order
+	// of Value and Scan.
+	rruleColumns = []string{
+		"freq", "interval", "count", "until", "bysecond", "byminute", "byhour", "byday",
+		"bymonthday", "byyearday", "byweekno", "bymonth", "bysetpos", "wkst", "byeaster",
+	}
+	// rruleLegacyColumns is the _rrule.RRULE composite before the byeaster
+	// migration, which Scan still reads.
+	rruleLegacyColumns = rruleColumns[:len(rruleColumns)-1]
+	// rrulesetColumns are the fields of the _rrule.RRULESET composite in the
+	// order of Scan.
+	rrulesetColumns = []string{"dtstart", "dtend", "rrule", "exrule", "rdate", "exdate"}
+)
+
+// Queryer runs queries, it is implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+const compositeColumnsQuery = `SELECT a.attname
+FROM pg_attribute a
+JOIN pg_class c ON c.oid = a.attrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = '_rrule' AND c.relname = $1 AND a.attnum > 0 AND NOT a.attisdropped
+ORDER BY a.attnum`
+
+// CheckSchema verifies that the _rrule.RRULE and _rrule.RRULESET composite
+// types of the database have the fields of Migrations in the order read by
+// Scan. A database migrated before the byeaster version is accepted, as
+// Scan reads it too.
+func CheckSchema(ctx context.Context, db Queryer) error {
+	for _, c := range []struct {
+		name     string
+		accepted [][]string
+	}{
+		{"rrule", [][]string{rruleColumns, rruleLegacyColumns}},
+		{"rruleset", [][]string{rrulesetColumns}},
+	} {
+		columns, err := compositeColumns(ctx, db, c.name)
+		if err != nil {
+			return err
+		}
+		if err := checkColumns(c.name, columns, c.accepted...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func compositeColumns(ctx context.Context, db Queryer, name string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, compositeColumnsQuery, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+// checkColumns checks that the columns of the composite name are one of the
+// accepted layouts.
+func checkColumns(name string, columns []string, accepted ...[]string) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("%w: _rrule.%s does not exist", ErrSchemaMismatch, strings.ToUpper(name))
+	}
+	for _, want := range accepted {
+		if sameColumns(columns, want) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: _rrule.%s has (%s), want (%s)", ErrSchemaMismatch, strings.ToUpper(name),
+		strings.Join(columns, ","), strings.Join(accepted[0], ","))
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
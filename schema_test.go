@@ -0,0 +1,114 @@
+package rrule
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrations(t *testing.T) {
+	t.Parallel()
+	names, err := fs.Glob(Migrations, "migrations/*.sql")
+	assert.NoError(t, err)
+	sort.Strings(names)
+	var versions []string
+	for _, name := range names {
+		if version, ok := strings.CutSuffix(name, ".up.sql"); ok {
+			versions = append(versions, version)
+			assert.Contains(t, names, version+".down.sql")
+		}
+	}
+	assert.Len(t, names, 2*len(versions))
+	assert.True(t, strings.HasPrefix(versions[len(versions)-1], "migrations/20230702_"))
+
+	// Replay the column changes of the composites in order.
+	create := regexp.MustCompile(`(?s)CREATE TABLE _rrule\.(RRULE|RRULESET) \((.*?)\n\);`)
+	column := regexp.MustCompile(`(?m)^\s+"(\w+)"`)
+	add := regexp.MustCompile(`ALTER TABLE _rrule\.(RRULE|RRULESET)\s+ADD COLUMN IF NOT EXISTS "(\w+)"`)
+	columns := map[string][]string{}
+	for _, version := range versions {
+		b, err := fs.ReadFile(Migrations, version+".up.sql")
+		assert.NoError(t, err)
+		for _, m := range create.FindAllStringSubmatch(string(b), -1) {
+			for _, c := range column.FindAllStringSubmatch(m[2], -1) {
+				columns[m[1]] = append(columns[m[1]], c[1])
+			}
+		}
+		for _, m := range add.FindAllStringSubmatch(string(b), -1) {
+			columns[m[1]] = append(columns[m[1]], m[2])
+		}
+	}
+	assert.Equal(t, rruleColumns, columns["RRULE"])
+	assert.Equal(t, rrulesetColumns, columns["RRULESET"])
+}
+
+func TestCheckSchema(t *testing.T) {
+	t.Parallel()
+	legacy := append([]string(nil), rruleLegacyColumns...)
+	swapped := append([]string(nil), rruleColumns...)
+	swapped[1], swapped[2] = swapped[2], swapped[1]
+	cases := []struct {
+		composites map[string][]string
+		err        string
+	}{
+		{composites: map[string][]string{"rrule": rruleColumns, "rruleset": rrulesetColumns}},
+		{composites: map[string][]string{"rrule": legacy, "rruleset": rrulesetColumns}},
+		{
+			composites: map[string][]string{"rrule": swapped, "rruleset": rrulesetColumns},
+			err:        "schema mismatch: _rrule.RRULE has (freq,count,interval,",
+		},
+		{
+			composites: map[string][]string{"rrule": rruleColumns},
+			err:        "schema mismatch: _rrule.RRULESET does not exist",
+		},
+	}
+	for _, c := range cases {
+		db := sql.OpenDB(fakeCatalog(c.composites))
+		err := CheckSchema(context.Background(), db)
+		if c.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorIs(t, err, ErrSchemaMismatch)
+			assert.ErrorContains(t, err, c.err)
+		}
+		db.Close()
+	}
+}
+
+// fakeCatalog is a database answering the query of the composite columns.
+type fakeCatalog map[string][]string
+
+func (c fakeCatalog) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c fakeCatalog) Driver() driver.Driver                        { return nil }
+func (c fakeCatalog) Prepare(string) (driver.Stmt, error)          { return c, nil }
+func (c fakeCatalog) Close() error                                 { return nil }
+func (c fakeCatalog) Begin() (driver.Tx, error)                    { return nil, driver.ErrSkip }
+func (c fakeCatalog) NumInput() int                                { return 1 }
+func (c fakeCatalog) Exec([]driver.Value) (driver.Result, error)   { return nil, driver.ErrSkip }
+
+func (c fakeCatalog) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{columns: c[args[0].(string)]}, nil
+}
+
+type fakeRows struct {
+	columns []string
+}
+
+func (r *fakeRows) Columns() []string { return []string{"attname"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.columns) == 0 {
+		return io.EOF
+	}
+	dest[0], r.columns = r.columns[0], r.columns[1:]
+	return nil
+}
@@ -5,9 +5,16 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/kiraxie/rrule-go/internal/composite"
 )
 
+// Value returns the text of the _rrule.RRULE composite. It returns
+// ErrNotRepresentable for a rule with options the composite has no field for.
 func (t RRule) Value() (driver.Value, error) {
+	if err := t.CheckComposite(); err != nil {
+		return nil, err
+	}
 	s := []string{}
 	s = append(s, t.freq.String())
 	if t.interval != 0 {
@@ -20,66 +27,120 @@ func (t RRule) Value() (driver.Value, error) {
 	} else {
 		s = append(s, "")
 	}
-	if !t.until.IsZero() {
-		s = append(s, fmt.Sprintf("\"%s\"", t.until.Format(time.DateTime)))
+	if !t.Options.Until.IsZero() {
+		s = append(s, fmt.Sprintf("\"%s\"", t.Options.Until.UTC().Format(time.DateTime)))
 	} else {
 		s = append(s, "")
 	}
-	if len(t.bysecond) != 0 {
-		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.bysecond), ",")))
+	if len(t.Options.Bysecond) != 0 {
+		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.Options.Bysecond), ",")))
 	} else {
 		s = append(s, "")
 	}
-	if len(t.byminute) != 0 {
-		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.byminute), ",")))
+	if len(t.Options.Byminute) != 0 {
+		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.Options.Byminute), ",")))
 	} else {
 		s = append(s, "")
 	}
-	if len(t.byhour) != 0 {
-		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.byhour), ",")))
+	if len(t.Options.Byhour) != 0 {
+		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.Options.Byhour), ",")))
 	} else {
 		s = append(s, "")
 	}
-	if len(t.bydays) != 0 {
-		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(weekdaySliceToStringSlice(t.bydays), ",")))
+	if len(t.Options.Byweekday) != 0 {
+		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(weekdaySliceToStringSlice(t.Options.Byweekday), ",")))
 	} else {
 		s = append(s, "")
 	}
-	if len(t.bymonthday) != 0 {
-		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.bymonthday), ",")))
+	if len(t.Options.Bymonthday) != 0 {
+		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.Options.Bymonthday), ",")))
 	} else {
 		s = append(s, "")
 	}
-	if len(t.byyearday) != 0 {
-		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.byyearday), ",")))
+	if len(t.Options.Byyearday) != 0 {
+		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.Options.Byyearday), ",")))
 	} else {
 		s = append(s, "")
 	}
-	if len(t.byweekno) != 0 {
-		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.byweekno), ",")))
+	if len(t.Options.Byweekno) != 0 {
+		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.Options.Byweekno), ",")))
 	} else {
 		s = append(s, "")
 	}
-	if len(t.bymonth) != 0 {
-		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.bymonth), ",")))
+	if len(t.Options.Bymonth) != 0 {
+		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.Options.Bymonth), ",")))
 	} else {
 		s = append(s, "")
 	}
-	if len(t.bysetpos) != 0 {
-		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.bysetpos), ",")))
+	if len(t.Options.Bysetpos) != 0 {
+		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.Options.Bysetpos), ",")))
 	} else {
 		s = append(s, "")
 	}
 	s = append(s, Weekday{weekday: t.wkst}.String())
+	// byeaster is an extension field appended to the composite type by
+	// migrations/20230702_rrule_byeaster.up.sql.
+	if len(t.Options.Byeaster) != 0 {
+		s = append(s, fmt.Sprintf("\"{%s}\"", strings.Join(intSliceToStringSlice(t.Options.Byeaster), ",")))
+	} else {
+		s = append(s, "")
+	}
 
 	return fmt.Sprintf("(%s)", strings.Join(s, ",")), nil
 }
 
+// CheckComposite returns ErrNotRepresentable listing the options of the rule
+// that the _rrule.RRULE composite would drop. Value and the codec of the
+// pgxrrule package reject these rules.
+func (t *RRule) CheckComposite() error {
+	var unsupported []string
+	if t.calendar != nil {
+		unsupported = append(unsupported, "RSCALE="+t.calendar.Name())
+	}
+	if t.skip != Omit {
+		unsupported = append(unsupported, "SKIP="+t.skip.String())
+	}
+	if len(t.OrigOptions.Byleapmonth) != 0 {
+		unsupported = append(unsupported, "leap months of BYMONTH")
+	}
+	if len(t.bybusinessday) != 0 {
+		unsupported = append(unsupported, "BYBUSINESSDAY")
+	}
+	// _rrule.DAY has no ordinal, e.g. -1FR.
+	for _, wday := range t.Options.Byweekday {
+		if wday.N() != 0 {
+			unsupported = append(unsupported, "BYDAY="+wday.String())
+		}
+	}
+	if len(unsupported) != 0 {
+		return fmt.Errorf("%w: %s", ErrNotRepresentable, strings.Join(unsupported, ", "))
+	}
+
+	return nil
+}
+
+// Scan reads the text of the _rrule.RRULE composite, or the RFC 5545 text of
+// String.
 func (t *RRule) Scan(value interface{}) (err error) {
-	s := strings.Trim(value.(string), "()")
-	values := strings.Split(s, ",")
-	if len(values) != 14 {
-		return ErrInvalidRRuleFormat
+	s, err := scanText(value)
+	if err != nil {
+		return err
+	}
+	if !isCompositeValue(s) {
+		r, err := StrToRRule(s)
+		if err != nil {
+			return err
+		}
+		*t = *r
+		return nil
+	}
+	values, err := splitCompositeValue(s)
+	if err != nil {
+		return err
+	}
+	// The composite type lacks byeaster before the extension migration.
+	if len(values) != 14 && len(values) != 15 {
+		return fmt.Errorf("%w: %s(%d)", ErrInvalidRRuleFormat, s, len(values))
 	}
 	opt := ROption{}
 	if err = opt.Freq.Parse(values[0]); err != nil {
@@ -124,6 +185,11 @@ func (t *RRule) Scan(value interface{}) (err error) {
 	if err = opt.Wkst.Parse(values[13]); err != nil {
 		return
 	}
+	if len(values) == 15 {
+		if opt.Byeaster, err = parseIntSlice(values[14]); err != nil {
+			return
+		}
+	}
 
 	v, err := NewRRule(opt)
 	if err != nil {
@@ -134,79 +200,208 @@ func (t *RRule) Scan(value interface{}) (err error) {
 	return nil
 }
 
+// splitCompositeValue splits the text representation of a PostgreSQL
+// composite value into its fields, removing the quotes around them.
+func splitCompositeValue(s string) ([]string, error) {
+	fields, err := composite.Split(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRRuleFormat, err)
+	}
+
+	return fields, nil
+}
+
+// Value returns the text of the _rrule.RRULESET composite, its exrule and
+// dtend being NULL. Its TIMESTAMP fields have no time zone, so the times are
+// written in UTC and Scan reads them back in UTC. It returns
+// ErrNotRepresentable for the sets rejected by CheckComposite.
 func (t Set) Value() (driver.Value, error) {
-	return "", nil
-}
-
-func splitRRuleSetValue(s string) (element []string, err error) {
-	symbols := []string{}
-	for prev, pos := 0, 0; pos < len(s); pos++ {
-		switch s[pos] {
-		case ',':
-			if len(symbols) == 0 {
-				element = append(element, s[prev:pos])
-				prev = pos + 1
-			}
-		case '(':
-			if len(element) == 0 { // the front of the string
-				prev = pos + 1
-
-				continue
-			}
-			symbols = append(symbols, "(")
-			prev = pos
-		case ')':
-			switch {
-			case len(element) == 5 && len(symbols) == 0: // the end of the string
-				element = append(element, s[prev:pos])
-			case len(symbols) == 0 || symbols[len(symbols)-1] != "(":
-				return nil, fmt.Errorf("%w: symbol \")\" %s", ErrInvalidRRuleFormat, s)
-			default:
-				symbols = symbols[:len(symbols)-1]
-				element = append(element, s[prev:pos+1])
-				pos += 2
-				prev = pos + 1
-			}
-		case '{':
-			symbols = append(symbols, "{")
-		case '}':
-			if len(symbols) == 0 || symbols[len(symbols)-1] != "{" {
-				return nil, fmt.Errorf("%w: symbol \"}\" %s", ErrInvalidRRuleFormat, s)
-			}
-			symbols = symbols[:len(symbols)-1]
-			element = append(element, s[prev:pos+1])
-			pos += 2
-			prev = pos + 1
-		default:
+	if err := t.CheckComposite(); err != nil {
+		return nil, err
+	}
+	s := make([]string, 6)
+	if !t.dtstart.IsZero() {
+		s[0] = quoteCompositeField(t.dtstart.UTC().Format(time.DateTime))
+	}
+	if t.rrule != nil {
+		v, err := t.rrule.Value()
+		if err != nil {
+			return nil, err
 		}
+		s[2] = quoteCompositeField(v.(string))
 	}
-	if len(element) != 6 {
-		return nil, fmt.Errorf("%w: %s(%d)", ErrInvalidRRuleFormat, s, len(element))
+	s[4] = dateArrayValue(t.rdate)
+	s[5] = dateArrayValue(t.exdate)
+
+	return fmt.Sprintf("(%s)", strings.Join(s, ",")), nil
+}
+
+// CheckComposite returns ErrNotRepresentable when the set does not fit the
+// _rrule.RRULESET composite: its rule does not, see RRule.CheckComposite, or
+// the DTSTART of the rule is not in UTC. The TIMESTAMP fields have no time
+// zone, while the occurrences of a rule follow the wall clock of its DTSTART,
+// e.g. across DST.
+func (set *Set) CheckComposite() error {
+	if set.rrule == nil {
+		return nil
+	}
+	if dtstart := set.rrule.dtstart; !isUTC(dtstart.Location(), dtstart.Year()) {
+		return fmt.Errorf("%w: DTSTART of a rule in %s", ErrNotRepresentable, dtstart.Location())
 	}
 
-	return element, nil
+	return set.rrule.CheckComposite()
+}
+
+// quoteCompositeField quotes a field of a composite value, doubling the
+// quotes and backslashes in it.
+func quoteCompositeField(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	return "\"" + strings.ReplaceAll(s, "\"", "\"\"") + "\""
+}
+
+func dateArrayValue(dates []time.Time) string {
+	if len(dates) == 0 {
+		return ""
+	}
+	s := make([]string, len(dates))
+	for i, dt := range dates {
+		s[i] = fmt.Sprintf("\"%s\"", dt.UTC().Format(time.DateTime))
+	}
+	return quoteCompositeField(fmt.Sprintf("{%s}", strings.Join(s, ",")))
 }
 
+// Scan reads the text of the _rrule.RRULESET composite, or the RFC 5545 text
+// of String. The exrule of the composite is not supported.
 func (t *Set) Scan(value interface{}) (err error) {
-	var element []string
-	if element, err = splitRRuleSetValue(value.(string)); err != nil {
-		return
+	s, err := scanText(value)
+	if err != nil {
+		return err
 	}
-	if t.dtstart, err = parseDate(element[0]); err != nil {
-		return
+	if !isCompositeValue(s) {
+		set, err := StrToRRuleSet(s)
+		if err != nil {
+			return err
+		}
+		*t = *set
+		return nil
 	}
-	if element[2] != "" {
-		t.rrule = &RRule{}
-		if err = t.rrule.Scan(element[2]); err != nil {
-			return
+	fields, err := splitCompositeValue(s)
+	if err != nil {
+		return err
+	}
+	if len(fields) != 6 {
+		return fmt.Errorf("%w: %s(%d)", ErrInvalidRRuleFormat, s, len(fields))
+	}
+	if fields[3] != "" {
+		return fmt.Errorf("%w: exrule is not supported", ErrInvalidRRuleFormat)
+	}
+	set := Set{}
+	dtstart, err := parseDate(fields[0])
+	if err != nil {
+		return err
+	}
+	if !dtstart.IsZero() {
+		set.DTStart(dtstart)
+	}
+	if fields[2] != "" {
+		// The scanned rule has no DTSTART, it defaulted to the current time.
+		if dtstart.IsZero() {
+			return fmt.Errorf("%w: rrule without dtstart", ErrInvalidRRuleFormat)
+		}
+		r := &RRule{}
+		if err = r.Scan(fields[2]); err != nil {
+			return err
 		}
+		set.RRule(r.WithDTStart(dtstart))
 	}
-	if t.rdate, err = parseDateSlice(element[4]); err != nil {
-		return
+	rdates, err := parseDateSlice(fields[4])
+	if err != nil {
+		return err
 	}
-	if t.exdate, err = parseDateSlice(element[5]); err != nil {
-		return
+	exdates, err := parseDateSlice(fields[5])
+	if err != nil {
+		return err
 	}
+	set.SetRDates(rdates)
+	set.SetExDates(exdates)
+	*t = set
 
 	return nil
 }
+
+// scanText returns the text of a value read by a driver.
+func scanText(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	default:
+		return "", fmt.Errorf("%w: %T", ErrInvalidRRuleFormat, value)
+	}
+}
+
+// isCompositeValue tells whether s is the text of a composite rather than
+// RFC 5545 text.
+func isCompositeValue(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), "(")
+}
+
+// text returns the RFC 5545 text of the rule, with its DTSTART even when it
+// defaulted to the current time.
+func (t *RRule) text() string {
+	option := t.OrigOptions
+	option.Dtstart = t.dtstart
+	return option.String()
+}
+
+// TextRRule is an RRule stored as its RFC 5545 text, e.g. in a TEXT column of
+// SQLite or MySQL. Scan reads the _rrule.RRULE composite as well, so that the
+// rows written before a migration to TEXT still load.
+type TextRRule struct {
+	RRule
+}
+
+// Value returns the RFC 5545 text of the rule with its DTSTART, or nil for
+// the zero TextRRule, which was never built by NewRRule.
+func (t TextRRule) Value() (driver.Value, error) {
+	if t.dtstart.IsZero() {
+		return nil, nil
+	}
+	return t.text(), nil
+}
+
+// Scan reads the RFC 5545 text or the _rrule.RRULE composite of RRule.Scan,
+// NULL being the zero TextRRule written by Value.
+func (t *TextRRule) Scan(value interface{}) error {
+	if value == nil {
+		*t = TextRRule{}
+		return nil
+	}
+	return t.RRule.Scan(value)
+}
+
+// TextSet is a Set stored as its RFC 5545 text, e.g. in a TEXT column of
+// SQLite or MySQL. Scan reads the _rrule.RRULESET composite as well, so that
+// the rows written before a migration to TEXT still load.
+type TextSet struct {
+	Set
+}
+
+// Value returns the RFC 5545 text of String, or nil for the zero TextSet.
+func (t TextSet) Value() (driver.Value, error) {
+	if t.dtstart.IsZero() && t.rrule == nil && len(t.rdate) == 0 && len(t.exdate) == 0 {
+		return nil, nil
+	}
+	return t.String(), nil
+}
+
+// Scan reads the RFC 5545 text or the _rrule.RRULESET composite of Set.Scan,
+// NULL being the zero TextSet written by Value.
+func (t *TextSet) Scan(value interface{}) error {
+	if value == nil {
+		*t = TextSet{}
+		return nil
+	}
+	return t.Set.Scan(value)
+}
@@ -0,0 +1,180 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRRuleValueScan(t *testing.T) {
+	t.Parallel()
+	r, err := NewRRule(ROption{
+		Freq:      Yearly,
+		Until:     time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		Byweekday: []Weekday{Monday, Friday},
+		Byhour:    []int{9, 17},
+		Byeaster:  []int{-2, 0, 1},
+	})
+	assert.NoError(t, err)
+	value, err := r.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `(YEARLY,1,,"2030-01-01 00:00:00",,,"{9,17}","{MO,FR}",,,,,,MO,"{-2,0,1}")`, value)
+
+	var scanned RRule
+	assert.NoError(t, scanned.Scan(value))
+	assert.Equal(t, r.Options.Until, scanned.Options.Until)
+	assert.Equal(t, []int{9, 17}, scanned.Options.Byhour)
+	assert.Equal(t, []Weekday{Monday, Friday}, scanned.Options.Byweekday)
+	assert.Equal(t, []int{-2, 0, 1}, scanned.Options.Byeaster)
+
+	// Rows stored before the byeaster column was added.
+	assert.NoError(t, scanned.Scan(`(DAILY,2,5,,,,,,,,,,,MO)`))
+	assert.Equal(t, Daily, scanned.Options.Freq)
+	assert.Empty(t, scanned.Options.Byeaster)
+	assert.Error(t, scanned.Scan(`(DAILY,2,5)`))
+
+	// The composite has no field for the options of RFC 7529, BYBUSINESSDAY and
+	// the ordinals of BYDAY.
+	for _, rule := range []string{
+		"RRULE:RSCALE=HEBREW;FREQ=YEARLY;BYMONTH=5L",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=31;SKIP=BACKWARD",
+		"RRULE:RSCALE=CHINESE;FREQ=YEARLY",
+		"RRULE:FREQ=MONTHLY;BYDAY=-1FR",
+	} {
+		r, err := StrToRRule(rule)
+		assert.NoError(t, err, rule)
+		_, err = r.Value()
+		assert.ErrorIs(t, err, ErrNotRepresentable, rule)
+	}
+	r, err = NewRRule(ROption{Freq: Monthly, Bybusinessday: []int{-1}})
+	assert.NoError(t, err)
+	_, err = r.Value()
+	assert.ErrorIs(t, err, ErrNotRepresentable)
+	r, err = StrToRRule("RRULE:RSCALE=GREGORIAN;FREQ=DAILY;COUNT=2")
+	assert.NoError(t, err)
+	value, err = r.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `(DAILY,1,2,,,,,,,,,,,MO,)`, value)
+}
+
+func TestSetValueScan(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	r, _ := NewRRule(ROption{Freq: Weekly, Count: 4, Byweekday: []Weekday{Monday, Friday}})
+	set := Set{}
+	set.DTStart(dtstart)
+	set.RRule(r)
+	set.RDate(time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC))
+	set.ExDate(time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC))
+
+	value, err := set.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `("2024-01-01 09:00:00",,"(WEEKLY,1,4,,,,,""{MO,FR}"",,,,,,MO,)",,"{""2024-01-03 12:00:00""}","{""2024-01-05 09:00:00""}")`, value)
+
+	var scanned Set
+	assert.NoError(t, scanned.Scan(value))
+	assert.Equal(t, set.All(), scanned.All())
+	assert.NoError(t, scanned.Scan([]byte(`("2024-01-01 09:00:00",,,,"{""2024-01-03 12:00:00"",""2024-01-02 12:00:00""}",)`)))
+	assert.Nil(t, scanned.GetRRule())
+	assert.Equal(t, []time.Time{time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC), time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)}, scanned.All())
+
+	// The RFC 5545 text of String is read as well.
+	assert.NoError(t, scanned.Scan(set.String()))
+	assert.Equal(t, set.All(), scanned.All())
+	var rule RRule
+	assert.NoError(t, rule.Scan([]byte(r.WithDTStart(dtstart).String())))
+	assert.Equal(t, set.GetRRule().All(), rule.All())
+
+	assert.ErrorIs(t, scanned.Scan(`("2024-01-01 09:00:00",,,"(DAILY,1,,,,,,,,,,,,MO,)",,)`), ErrInvalidRRuleFormat)
+	assert.ErrorIs(t, scanned.Scan(`(,,"(DAILY,1,2,,,,,,,,,,,MO,)",,,)`), ErrInvalidRRuleFormat)
+	assert.ErrorIs(t, scanned.Scan(1), ErrInvalidRRuleFormat)
+}
+
+func TestSetValueScanTZID(t *testing.T) {
+	t.Parallel()
+	paris, err := time.LoadLocation("Europe/Paris")
+	assert.NoError(t, err)
+
+	// The dates are written in UTC and read back as the same instants.
+	set := Set{}
+	set.DTStart(time.Date(2024, 3, 30, 9, 0, 0, 0, paris))
+	set.RDate(time.Date(2024, 3, 30, 9, 0, 0, 0, paris))
+	set.RDate(time.Date(2024, 3, 31, 9, 0, 0, 0, paris))
+	set.ExDate(time.Date(2024, 4, 1, 9, 0, 0, 0, paris))
+	value, err := set.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `("2024-03-30 08:00:00",,,,"{""2024-03-30 08:00:00"",""2024-03-31 07:00:00""}","{""2024-04-01 07:00:00""}")`, value)
+	var scanned Set
+	assert.NoError(t, scanned.Scan(value))
+	all := scanned.All()
+	if assert.Len(t, all, 2) {
+		assert.True(t, all[0].Equal(time.Date(2024, 3, 30, 9, 0, 0, 0, paris)))
+		assert.True(t, all[1].Equal(time.Date(2024, 3, 31, 9, 0, 0, 0, paris)))
+	}
+
+	// A rule follows the wall clock of its TZID, which a TIMESTAMP loses.
+	r, _ := NewRRule(ROption{Freq: Daily, Count: 2})
+	set.RRule(r)
+	_, err = set.Value()
+	assert.ErrorIs(t, err, ErrNotRepresentable)
+	// Etc/UTC and a fixed zone without offset have the wall clock of UTC.
+	utc, err := time.LoadLocation("Etc/UTC")
+	assert.NoError(t, err)
+	for _, loc := range []*time.Location{utc, time.FixedZone("Z", 0)} {
+		set.DTStart(time.Date(2024, 3, 30, 9, 0, 0, 0, loc))
+		value, err = set.Value()
+		assert.NoError(t, err, loc)
+		assert.NoError(t, scanned.Scan(value))
+		assert.Equal(t, []time.Time{
+			time.Date(2024, 3, 30, 9, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC),
+		}, scanned.GetRRule().All())
+	}
+}
+
+func TestTextValueScan(t *testing.T) {
+	t.Parallel()
+	dtstart := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	r, _ := NewRRule(ROption{Freq: Weekly, Count: 3, Byweekday: []Weekday{Monday, Friday}, Dtstart: dtstart})
+	rule := TextRRule{*r}
+	value, err := rule.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "DTSTART:20240101T090000Z\nRRULE:FREQ=WEEKLY;COUNT=3;BYDAY=MO,FR", value)
+	// The zero values are NULL, which is read back into them.
+	value, err = TextRRule{}.Value()
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+	scannedRule := rule
+	assert.NoError(t, scannedRule.Scan(value))
+	assert.Equal(t, TextRRule{}, scannedRule)
+	value, err = TextSet{}.Value()
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+	scannedSet := TextSet{}
+	scannedSet.RRule(r)
+	assert.NoError(t, scannedSet.Scan(value))
+	assert.Equal(t, TextSet{}, scannedSet)
+
+	set := TextSet{}
+	set.RRule(r)
+	set.ExDate(time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC))
+	value, err = set.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "DTSTART:20240101T090000Z\nRRULE:FREQ=WEEKLY;COUNT=3;BYDAY=MO,FR\nEXDATE:20240105T090000Z", value)
+
+	// Rows of a column migrating from the composites to TEXT hold both.
+	composite, _ := set.Set.Value()
+	for _, v := range []interface{}{value, []byte(value.(string)), composite} {
+		var scanned TextSet
+		assert.NoError(t, scanned.Scan(v))
+		assert.Equal(t, []time.Time{dtstart, time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC)}, scanned.All())
+	}
+	composite, _ = r.Value()
+	for _, v := range []interface{}{rule.text(), composite} {
+		var scanned TextRRule
+		assert.NoError(t, scanned.Scan(v))
+		assert.Equal(t, Weekly, scanned.Options.Freq)
+		assert.Equal(t, []Weekday{Monday, Friday}, scanned.Options.Byweekday)
+	}
+}
@@ -16,6 +16,12 @@ const (
 	LocalDateTimeFormat = "20060102T150405"
 	// DateFormat is date format used in iCalendar (RFC 5545)
 	DateFormat = "20060102"
+	// BusinessDayProperty is the extension property of the rules with
+	// BYBUSINESSDAY, which is not a RRULE part of RFC 5545. Its value is a
+	// RRULE value, e.g. the third business day of each month is
+	//
+	//	X-BUSINESSDAY-RRULE:FREQ=MONTHLY;BYBUSINESSDAY=3
+	BusinessDayProperty = "X-BUSINESSDAY-RRULE"
 )
 
 func timeToStr(time time.Time) string {
@@ -56,6 +62,24 @@ func appendIntsOption(options []string, key string, value []int) []string {
 	return append(options, fmt.Sprintf("%s=%s", key, strings.Join(valueStr, ",")))
 }
 
+// strToMonths parses BYMONTH values, leap months carrying an "L" suffix as
+// of RFC 7529.
+func strToMonths(value string) (months, leapMonths []int, err error) {
+	for _, s := range strings.Split(value, ",") {
+		leap := strings.HasSuffix(s, "L")
+		month, e := strconv.Atoi(strings.TrimSuffix(s, "L"))
+		if e != nil {
+			return nil, nil, e
+		}
+		if leap {
+			leapMonths = append(leapMonths, month)
+		} else {
+			months = append(months, month)
+		}
+	}
+	return months, leapMonths, nil
+}
+
 func strToInts(value string) ([]int, error) {
 	contents := strings.Split(value, ",")
 	result := make([]int, len(contents))
@@ -79,12 +103,27 @@ func (option *ROption) String() string {
 		return str
 	}
 
-	return fmt.Sprintf("DTSTART%s\nRRULE:%s", timeToRFCDatetimeStr(option.Dtstart), str)
+	return fmt.Sprintf("DTSTART%s\n%s:%s", timeToRFCDatetimeStr(option.Dtstart), option.propertyName(), str)
+}
+
+// propertyName returns the name of the property holding the rule.
+func (option *ROption) propertyName() string {
+	if len(option.Bybusinessday) != 0 {
+		return BusinessDayProperty
+	}
+	return "RRULE"
 }
 
 // RRuleString returns RRULE string exclude DTSTART
 func (option *ROption) RRuleString() string {
-	result := []string{fmt.Sprintf("FREQ=%v", option.Freq)}
+	result := []string{}
+	if option.Rscale != "" {
+		result = append(result, fmt.Sprintf("RSCALE=%s", strings.ToUpper(option.Rscale)))
+	} else if option.Skip != Omit {
+		// SKIP MUST only be present with RSCALE, RFC 7529 section 4.1.
+		result = append(result, fmt.Sprintf("RSCALE=%s", Gregorian.Name()))
+	}
+	result = append(result, fmt.Sprintf("FREQ=%v", option.Freq))
 	if option.Interval != 0 {
 		result = append(result, fmt.Sprintf("INTERVAL=%v", option.Interval))
 	}
@@ -97,8 +136,19 @@ func (option *ROption) RRuleString() string {
 	if !option.Until.IsZero() {
 		result = append(result, fmt.Sprintf("UNTIL=%v", timeToStr(option.Until)))
 	}
+	if option.Skip != Omit {
+		result = append(result, fmt.Sprintf("SKIP=%v", option.Skip))
+	}
 	result = appendIntsOption(result, "BYSETPOS", option.Bysetpos)
-	result = appendIntsOption(result, "BYMONTH", option.Bymonth)
+	if len(option.Byleapmonth) != 0 {
+		valueStr := intSliceToStringSlice(option.Bymonth)
+		for _, month := range option.Byleapmonth {
+			valueStr = append(valueStr, fmt.Sprintf("%dL", month))
+		}
+		result = append(result, fmt.Sprintf("BYMONTH=%s", strings.Join(valueStr, ",")))
+	} else {
+		result = appendIntsOption(result, "BYMONTH", option.Bymonth)
+	}
 	result = appendIntsOption(result, "BYMONTHDAY", option.Bymonthday)
 	result = appendIntsOption(result, "BYYEARDAY", option.Byyearday)
 	result = appendIntsOption(result, "BYWEEKNO", option.Byweekno)
@@ -113,10 +163,13 @@ func (option *ROption) RRuleString() string {
 	result = appendIntsOption(result, "BYMINUTE", option.Byminute)
 	result = appendIntsOption(result, "BYSECOND", option.Bysecond)
 	result = appendIntsOption(result, "BYEASTER", option.Byeaster)
+	result = appendIntsOption(result, "BYBUSINESSDAY", option.Bybusinessday)
 	return strings.Join(result, ";")
 }
 
 // StrToROption converts string to ROption.
+// A rule with BYBUSINESSDAY is given by the BusinessDayProperty instead of
+// RRULE, as in StrSliceToRRuleSetInLoc.
 func StrToROption(rfcString string) (*ROption, error) {
 	return StrToROptionInLocation(rfcString, time.UTC)
 }
@@ -156,7 +209,9 @@ func StrToROptionInLocation(rfcString string, loc *time.Location) (*ROption, err
 		}
 	}
 
+	isRRule := strings.HasPrefix(rruleStr, "RRULE:")
 	rruleStr = strings.TrimPrefix(rruleStr, "RRULE:")
+	rruleStr = strings.TrimPrefix(rruleStr, BusinessDayProperty+":")
 	for _, attr := range strings.Split(rruleStr, ";") {
 		keyValue := strings.Split(attr, "=")
 		if len(keyValue) != 2 {
@@ -184,7 +239,7 @@ func StrToROptionInLocation(rfcString string, loc *time.Location) (*ROption, err
 		case "BYSETPOS":
 			result.Bysetpos, e = strToInts(value)
 		case "BYMONTH":
-			result.Bymonth, e = strToInts(value)
+			result.Bymonth, result.Byleapmonth, e = strToMonths(value)
 		case "BYMONTHDAY":
 			result.Bymonthday, e = strToInts(value)
 		case "BYYEARDAY":
@@ -201,6 +256,13 @@ func StrToROptionInLocation(rfcString string, loc *time.Location) (*ROption, err
 			result.Bysecond, e = strToInts(value)
 		case "BYEASTER":
 			result.Byeaster, e = strToInts(value)
+		case "BYBUSINESSDAY":
+			result.Bybusinessday, e = strToInts(value)
+		case "RSCALE":
+			result.Rscale = strings.ToUpper(value)
+			_, e = calendarSystemOf(result.Rscale)
+		case "SKIP":
+			e = result.Skip.Parse(value)
 		default:
 			return nil, fmt.Errorf("%w: unknown key %s", ErrInvalidRRuleFormat, key)
 		}
@@ -215,6 +277,9 @@ func StrToROptionInLocation(rfcString string, loc *time.Location) (*ROption, err
 		// a value from the options this returns.
 		return nil, fmt.Errorf("%w: FREQ is mandatory", ErrInvalidRRuleFormat)
 	}
+	if isRRule && len(result.Bybusinessday) != 0 {
+		return nil, fmt.Errorf("%w: BYBUSINESSDAY requires %s", ErrInvalidRRuleFormat, BusinessDayProperty)
+	}
 	return &result, nil
 }
 
@@ -229,21 +294,34 @@ func (set *Set) String() string {
 
 // StrToRRule converts string to RRule
 func StrToRRule(rfcString string) (*RRule, error) {
+	return StrToRRuleWithClock(rfcString, nil)
+}
+
+// StrToRRuleWithClock is same as StrToRRule, but a missing DTSTART defaults
+// to the current time of clock.
+func StrToRRuleWithClock(rfcString string, clock Clock) (*RRule, error) {
 	option, e := StrToROption(rfcString)
 	if e != nil {
 		return nil, e
 	}
+	option.Clock = clock
 	return NewRRule(*option)
 }
 
 // StrToRRuleSet converts string to RRuleSet
 func StrToRRuleSet(s string) (*Set, error) {
+	return StrToRRuleSetWithClock(s, nil)
+}
+
+// StrToRRuleSetWithClock is same as StrToRRuleSet, but a missing DTSTART
+// defaults to the current time of clock.
+func StrToRRuleSetWithClock(s string, clock Clock) (*Set, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
 		return nil, fmt.Errorf("%w: empty string", ErrInvalidRRuleFormat)
 	}
 	ss := strings.Split(s, "\n")
-	return StrSliceToRRuleSet(ss)
+	return strSliceToRRuleSet(ss, time.UTC, clock)
 }
 
 // StrSliceToRRuleSet converts given str slice to RRuleSet
@@ -254,8 +332,14 @@ func StrSliceToRRuleSet(ss []string) (*Set, error) {
 }
 
 // StrSliceToRRuleSetInLoc is same as StrSliceToRRuleSet, but by default parses local times
-// in specified default location
+// in specified default location.
+// A rule with BYBUSINESSDAY is given by the BusinessDayProperty instead of
+// RRULE, its business days default to weekdays, see RRule.BusinessCalendar.
 func StrSliceToRRuleSetInLoc(ss []string, defaultLoc *time.Location) (*Set, error) {
+	return strSliceToRRuleSet(ss, defaultLoc, nil)
+}
+
+func strSliceToRRuleSet(ss []string, defaultLoc *time.Location, clock Clock) (*Set, error) {
 	if len(ss) == 0 {
 		return &Set{}, nil
 	}
@@ -288,11 +372,15 @@ func StrSliceToRRuleSetInLoc(ss []string, defaultLoc *time.Location) (*Set, erro
 		rule := line[len(name)+1:]
 
 		switch name {
-		case "RRULE":
+		case "RRULE", BusinessDayProperty:
 			rOpt, err := StrToROptionInLocation(rule, defaultLoc)
 			if err != nil {
 				return nil, fmt.Errorf("StrToROption failed: %w", err)
 			}
+			if name == "RRULE" && len(rOpt.Bybusinessday) != 0 {
+				return nil, fmt.Errorf("%w: BYBUSINESSDAY requires %s", ErrInvalidRRuleFormat, BusinessDayProperty)
+			}
+			rOpt.Clock = clock
 			r, err := NewRRule(*rOpt)
 			if err != nil {
 				return nil, err
@@ -164,3 +164,132 @@ func (t *Weekday) Parse(s string) error {
 
 	return nil
 }
+
+// Skip denotes how a recurrence handles dates that do not exist in the
+// calendar, such as February 30 or a leap month in a common year.
+// See RFC 7529 section 4.1.
+type Skip int
+
+// Constants
+const (
+	// Omit drops invalid dates, which is the behavior of RFC 5545.
+	Omit Skip = iota
+	// Backward moves an invalid date to the previous valid one.
+	Backward
+	// Forward moves an invalid date to the next valid one.
+	Forward
+)
+
+// Parse sets t to the SKIP value s of RFC 7529, e.g. BACKWARD.
+func (t *Skip) Parse(s string) error {
+	switch s {
+	case "OMIT":
+		*t = Omit
+	case "BACKWARD":
+		*t = Backward
+	case "FORWARD":
+		*t = Forward
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidSkip, s)
+	}
+
+	return nil
+}
+
+func (t Skip) String() string {
+	switch t {
+	case Omit:
+		return "OMIT"
+	case Backward:
+		return "BACKWARD"
+	case Forward:
+		return "FORWARD"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Adjustment denotes how an occurrence that falls on a non business day is
+// moved to a business day of a Calendar.
+type Adjustment int
+
+// Constants
+const (
+	// Following moves an occurrence to the next business day.
+	Following Adjustment = iota
+	// Preceding moves an occurrence to the previous business day.
+	Preceding
+	// Nearest moves an occurrence to the closest business day, the next
+	// one on a tie.
+	Nearest
+)
+
+func (t *Adjustment) Parse(s string) error {
+	switch s {
+	case "FOLLOWING":
+		*t = Following
+	case "PRECEDING":
+		*t = Preceding
+	case "NEAREST":
+		*t = Nearest
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidAdjustment, s)
+	}
+
+	return nil
+}
+
+func (t Adjustment) String() string {
+	switch t {
+	case Following:
+		return "FOLLOWING"
+	case Preceding:
+		return "PRECEDING"
+	case Nearest:
+		return "NEAREST"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// MissedRun denotes how a Scheduler handles the occurrences missed while it
+// was not running or was late.
+type MissedRun int
+
+// Constants
+const (
+	// CatchUp runs the handler for every missed occurrence, in order.
+	CatchUp MissedRun = iota
+	// SkipMissed drops the missed occurrences.
+	SkipMissed
+	// Coalesce runs the handler once for the last of the due occurrences.
+	Coalesce
+)
+
+func (t *MissedRun) Parse(s string) error {
+	switch s {
+	case "CATCHUP":
+		*t = CatchUp
+	case "SKIP":
+		*t = SkipMissed
+	case "COALESCE":
+		*t = Coalesce
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidMissedRun, s)
+	}
+
+	return nil
+}
+
+func (t MissedRun) String() string {
+	switch t {
+	case CatchUp:
+		return "CATCHUP"
+	case SkipMissed:
+		return "SKIP"
+	case Coalesce:
+		return "COALESCE"
+	default:
+		return "UNKNOWN"
+	}
+}
@@ -5,9 +5,11 @@ package rrule
 import (
 	"fmt"
 	"math"
-	"strconv"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/kiraxie/rrule-go/internal/composite"
 )
 
 // MAXYEAR
@@ -182,11 +184,67 @@ func after(next Next, dt time.Time, inc bool) time.Time {
 	}
 }
 
+// countBetween counts the occurrences between after and before as between
+// returns them, from the count of the occurrences before a time.
+func countBetween(countBefore func(time.Time) int, contains func(time.Time) bool, after, before time.Time, inc bool) int {
+	if !before.After(after) {
+		if inc && before.Equal(after) && contains(before) {
+			return 1
+		}
+		return 0
+	}
+	n := countBefore(before) - countBefore(after)
+	if inc && contains(before) {
+		n++
+	} else if !inc && contains(after) {
+		n--
+	}
+	return n
+}
+
 type optInt struct {
 	Int     int
 	Defined bool
 }
 
+// sortOptInts sorts a list by Int in place, an insertion sort as the lists
+// are short and almost sorted.
+func sortOptInts(list []optInt) {
+	for i := 1; i < len(list); i++ {
+		for j := i; j > 0 && list[j].Int < list[j-1].Int; j-- {
+			list[j], list[j-1] = list[j-1], list[j]
+		}
+	}
+}
+
+// resetInts returns a zeroed slice of length n, reusing buf when possible.
+func resetInts(buf []int, n int) []int {
+	if cap(buf) < n {
+		return make([]int, n)
+	}
+	buf = buf[:n]
+	for i := range buf {
+		buf[i] = 0
+	}
+	return buf
+}
+
+// sortedInts returns a sorted copy of list.
+func sortedInts(list []int) []int {
+	result := append([]int(nil), list...)
+	sort.Ints(result)
+	return result
+}
+
+func optIntContains(list []optInt, elem int) bool {
+	for _, t := range list {
+		if t.Int == elem {
+			return true
+		}
+	}
+	return false
+}
+
 func parseDate(s string) (time.Time, error) {
 	if s == "" {
 		return time.Time{}, nil
@@ -196,21 +254,13 @@ func parseDate(s string) (time.Time, error) {
 	return time.Parse(time.DateTime, s)
 }
 
-func parseIntSlice(s string) (result []int, err error) {
-	if s == "" {
-		return nil, nil
-	}
-	s = strings.Trim(s, "{}")
-	slice := strings.Split(s, ",")
-	for _, v := range slice {
-		var i int
-		if i, err = strconv.Atoi(v); err != nil {
-			return nil, err
-		}
-		result = append(result, i)
+func parseIntSlice(s string) ([]int, error) {
+	v, err := composite.ParseIntArray(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRRuleFormat, err)
 	}
 
-	return
+	return v, nil
 }
 
 func parseWeekdaySlice(s string) (result []Weekday, err error) {
@@ -220,8 +270,8 @@ func parseWeekdaySlice(s string) (result []Weekday, err error) {
 	s = strings.Trim(s, "{}")
 	slice := strings.Split(s, ",")
 	result = make([]Weekday, len(slice))
-	for _, v := range slice {
-		if err := result[0].Parse(v); err != nil {
+	for i, v := range slice {
+		if err := result[i].Parse(v); err != nil {
 			return nil, err
 		}
 	}
@@ -239,18 +289,35 @@ func intSliceToStringSlice(s []int) (result []string) {
 
 func weekdaySliceToStringSlice(s []Weekday) (result []string) {
 	for _, v := range s {
-		result = append(result, strconv.Itoa(v.weekday))
+		result = append(result, v.String())
 	}
 
 	return
 }
 
+// isUTC reports whether loc keeps the wall clock of UTC around year, e.g.
+// time.UTC, "Etc/UTC" or time.Local on a host in UTC. The offset is checked
+// in January and July, so that DST of either hemisphere is seen.
+func isUTC(loc *time.Location, year int) bool {
+	if loc == time.UTC {
+		return true
+	}
+	for _, month := range []time.Month{time.January, time.July} {
+		if _, offset := time.Date(year, month, 1, 0, 0, 0, 0, loc).Zone(); offset != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
 func parseInt(s string) (int, error) {
-	if s == "" {
-		return 0, nil
+	v, err := composite.ParseInt(s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidRRuleFormat, err)
 	}
 
-	return strconv.Atoi(s)
+	return v, nil
 }
 
 func parseDateSlice(s string) (result []time.Time, err error) {
@@ -260,7 +327,6 @@ func parseDateSlice(s string) (result []time.Time, err error) {
 	s = strings.Trim(s, "\"{}")
 	slice := strings.Split(s, ",")
 	for _, v := range slice {
-		fmt.Println("v:", v)
 		t, err := parseDate(v)
 		if err != nil {
 			return nil, err